@@ -2,9 +2,11 @@ package sbmap
 
 import (
 	"fmt"
+	"hash/maphash"
 	"iter"
 	"math/rand"
 	"slices"
+	"strings"
 	"testing"
 
 	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
@@ -59,6 +61,294 @@ func BenchmarkSlotProbe(b *testing.B) {
 	}
 }
 
+func BenchmarkComparableHash(b *testing.B) {
+	b.Run("String/FastPath", benchmarkHash(ComparableHash[string](), "a medium sized key"))
+	b.Run("String/Comparable", benchmarkHash(comparableHash[string](), "a medium sized key"))
+	b.Run("Float64/FastPath", benchmarkHash(ComparableHash[float64](), 3.14))
+	b.Run("Float64/Comparable", benchmarkHash(comparableHash[float64](), 3.14))
+	b.Run("Float32/FastPath", benchmarkHash(ComparableHash[float32](), 3.14))
+	b.Run("Float32/Comparable", benchmarkHash(comparableHash[float32](), 3.14))
+	b.Run("Pointer/FastPath", benchmarkHash(ComparableHash[*int](), new(int)))
+	b.Run("Pointer/Comparable", benchmarkHash(comparableHash[*int](), new(int)))
+	b.Run("Bool/FastPath", benchmarkHash(ComparableHash[bool](), true))
+	b.Run("Bool/Comparable", benchmarkHash(comparableHash[bool](), true))
+	b.Run("Array/FastPath", benchmarkHash(ComparableHash[[4]int](), [4]int{1, 2, 3, 4}))
+	b.Run("Array/Comparable", benchmarkHash(comparableHash[[4]int](), [4]int{1, 2, 3, 4}))
+}
+
+func comparableHash[T comparable]() func(v T) uint64 {
+	return func(v T) uint64 {
+		return maphash.Comparable(_comparableSeed, v)
+	}
+}
+
+func benchmarkHash[T any](hash func(v T) uint64, v T) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = hash(v)
+		}
+	}
+}
+
+func BenchmarkIntHash(b *testing.B) {
+	const size = 100000
+	randomKeys := make([]uint64, size)
+	stridedKeys := make([]uint64, size)
+	randVals := rand.New(rand.NewSource(3))
+	for i := range size {
+		randomKeys[i] = randVals.Uint64()
+		stridedKeys[i] = uint64(i) * 128
+	}
+
+	hashes := map[string]func(v uint64) uint64{
+		"Identity": ComparableHash[uint64](),
+		"Mixed":    ComparableMixedHash[uint64](3),
+	}
+	inputs := map[string][]uint64{
+		"Random":  randomKeys,
+		"Strided": stridedKeys,
+	}
+	for _, hashName := range []string{"Identity", "Mixed"} {
+		for _, inputName := range []string{"Random", "Strided"} {
+			b.Run(
+				fmt.Sprintf("%s/%s", hashName, inputName),
+				func(b *testing.B) {
+					keys := inputs[inputName]
+					for b.Loop() {
+						h := NewCustom[uint64, uint64](
+							_defaultInitialCap,
+							ComparableEqual[uint64],
+							hashes[hashName],
+						)
+						for _, k := range keys {
+							h.Put(k, k)
+						}
+						for _, k := range keys {
+							_, _ = h.Get(k)
+						}
+					}
+				},
+			)
+		}
+	}
+}
+
+type (
+	int32Hasher   struct{}
+	hashableInt32 int32
+)
+
+func (_ int32Hasher) Hash(v int32) uint64 {
+	return uint64(v)
+}
+
+func (_ int32Hasher) Equal(l int32, r int32) bool {
+	return l == r
+}
+
+func (v hashableInt32) Hash() uint64 {
+	return uint64(v)
+}
+
+func (v hashableInt32) Equal(other hashableInt32) bool {
+	return v == other
+}
+
+func BenchmarkHasher(b *testing.B) {
+	const size = 100000
+	keys := make([]int32, size)
+	hashableKeys := make([]hashableInt32, size)
+	randVals := rand.New(rand.NewSource(3))
+	for i := range size {
+		keys[i] = randVals.Int31()
+		hashableKeys[i] = hashableInt32(keys[i])
+	}
+
+	b.Run("NewCustom", benchmarkPutAndGet(
+		func() Map[int32, int32] {
+			return NewCustom[int32, int32](
+				_defaultInitialCap, ComparableEqual[int32], ComparableHash[int32](),
+			)
+		},
+		keys,
+	))
+	b.Run("NewHasher", benchmarkPutAndGet(
+		func() HasherMap[int32, int32, int32Hasher] {
+			return NewHasher[int32, int32](_defaultInitialCap, int32Hasher{})
+		},
+		keys,
+	))
+	// The embedded map calls the hasher methods through its stored functions
+	// rather than directly
+	b.Run("NewHasher/Embedded", benchmarkPutAndGet(
+		func() Map[int32, int32] {
+			return NewHasher[int32, int32](_defaultInitialCap, int32Hasher{}).Map
+		},
+		keys,
+	))
+	b.Run("NewHashable", benchmarkPutAndGet(
+		NewHashable[hashableInt32, hashableInt32],
+		hashableKeys,
+	))
+	// The embedded map calls the key methods through its stored functions
+	// rather than directly
+	b.Run("NewHashable/Embedded", benchmarkPutAndGet(
+		func() Map[hashableInt32, hashableInt32] {
+			return NewHashable[hashableInt32, hashableInt32]().Map
+		},
+		hashableKeys,
+	))
+}
+
+func BenchmarkCaseInsensitive(b *testing.B) {
+	const size = 10000
+	keys := make([]string, size)
+	upperKeys := make([]string, size)
+	for i := range size {
+		keys[i] = fmt.Sprintf("X-Header-Name-%d", i)
+		upperKeys[i] = strings.ToUpper(keys[i])
+	}
+	seed := maphash.MakeSeed()
+
+	b.Run("ToLower", benchmarkCaseInsensitiveGet(
+		NewCustom[string, int](
+			_defaultInitialCap,
+			func(l, r string) bool { return strings.ToLower(l) == strings.ToLower(r) },
+			func(v string) uint64 { return maphash.String(seed, strings.ToLower(v)) },
+		),
+		keys, upperKeys,
+	))
+	b.Run("NewCaseInsensitive", benchmarkCaseInsensitiveGet(
+		NewCaseInsensitive[int](), keys, upperKeys,
+	))
+	b.Run("NewUnicodeFold", benchmarkCaseInsensitiveGet(
+		NewUnicodeFold[int](), keys, upperKeys,
+	))
+}
+
+func benchmarkCaseInsensitiveGet(
+	h Map[string, int],
+	keys []string,
+	lookupKeys []string,
+) func(b *testing.B) {
+	for i, k := range keys {
+		h.Put(k, i)
+	}
+	return func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, k := range lookupKeys {
+				_, _ = h.Get(k)
+			}
+		}
+	}
+}
+
+func BenchmarkBytesKeyMap(b *testing.B) {
+	const size = 10000
+	keys := make([][]byte, size)
+	h := NewBytesKey[int]()
+	for i := range size {
+		keys[i] = fmt.Appendf(nil, "key-%d", i)
+		h.PutBytes(keys[i], i)
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, k := range keys {
+				_, _ = h.Get(string(k))
+			}
+		}
+	})
+	b.Run("GetBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, k := range keys {
+				_, _ = h.GetBytes(k)
+			}
+		}
+	})
+}
+
+func BenchmarkFrozenMap(b *testing.B) {
+	for _, size := range []int{100, 10000, 1000000} {
+		randVals := rand.New(rand.NewSource(3))
+		keys := make([]int32, size)
+		missingKeys := make([]int32, size)
+		h := NewCustom[int32, int32](
+			_defaultInitialCap, ComparableEqual[int32], ComparableMixedHash[int32](0),
+		)
+		for i := range size {
+			keys[i] = randVals.Int31()
+			missingKeys[i] = -randVals.Int31() - 1
+			h.Put(keys[i], keys[i])
+		}
+		f := h.Freeze()
+
+		b.Run(fmt.Sprintf("Map/Hit/%d_Elements", size), benchmarkGet(h.Get, keys))
+		b.Run(fmt.Sprintf("FrozenMap/Hit/%d_Elements", size), benchmarkGet(f.Get, keys))
+		b.Run(fmt.Sprintf("Map/Miss/%d_Elements", size), benchmarkGet(h.Get, missingKeys))
+		b.Run(fmt.Sprintf("FrozenMap/Miss/%d_Elements", size), benchmarkGet(f.Get, missingKeys))
+	}
+}
+
+func BenchmarkPersistentMap(b *testing.B) {
+	const size = 10000
+	h := New[int32, int32]()
+	p := NewPersistent[int32, int32]()
+	randVals := rand.New(rand.NewSource(3))
+	for range size {
+		k := randVals.Int31()
+		h.Put(k, k)
+		p = p.With(k, k)
+	}
+
+	b.Run("CopyAndPut", func(b *testing.B) {
+		for b.Loop() {
+			c := h.Copy()
+			c.Put(-1, -1)
+		}
+	})
+	b.Run("With", func(b *testing.B) {
+		for b.Loop() {
+			_ = p.With(-1, -1)
+		}
+	})
+	b.Run("Map.Get", benchmarkGet(h.Get, slices.Collect(h.Keys())))
+	b.Run("PersistentMap.Get", benchmarkGet(p.Get, slices.Collect(h.Keys())))
+}
+
+func benchmarkGet[K any, V any](get func(k K) (V, bool), keys []K) func(b *testing.B) {
+	return func(b *testing.B) {
+		for b.Loop() {
+			for _, k := range keys {
+				_, _ = get(k)
+			}
+		}
+	}
+}
+
+func benchmarkPutAndGet[K any, M any, PM interface {
+	*M
+	Put(k K, v K)
+	Get(k K) (K, bool)
+}](newMap func() M, keys []K) func(b *testing.B) {
+	return func(b *testing.B) {
+		for b.Loop() {
+			m := newMap()
+			h := PM(&m)
+			for _, k := range keys {
+				h.Put(k, k)
+			}
+			for _, k := range keys {
+				_, _ = h.Get(k)
+			}
+		}
+	}
+}
+
 func BenchmarkBuiltinMap(b *testing.B) {
 	setupOps := setupOps[map[int32]int64]{
 		PutOp:    builtinMapEmptyInit,
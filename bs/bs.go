@@ -6,7 +6,8 @@ import (
 
 var (
 	testTargets       = []string{"nosimd", "128", "256", "512"}
-	testTargetsAndAll = append(testTargets, "all")
+	unitTestTargets   = append(testTargets, "randiter")
+	testTargetsAndAll = append(unitTestTargets, "all")
 )
 
 func main() {
@@ -31,6 +31,8 @@ func registerUnitTestTargets() {
 					return sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_simd256", "-v", "./...")
 				case "512":
 					return sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_simd512", "-v", "./...")
+				case "randiter":
+					return sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_randiter", "-v", "./...")
 				case "all":
 					err := sbbs.RunStdout(ctxt, "go", "test", "-v", "./...")
 					if err != nil {
@@ -44,7 +46,11 @@ func registerUnitTestTargets() {
 					if err != nil {
 						return err
 					}
-					return sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_simd512", "-v", "./...")
+					err = sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_simd512", "-v", "./...")
+					if err != nil {
+						return err
+					}
+					return sbbs.RunStdout(ctxt, "go", "test", "-tags=sbmap_randiter", "-v", "./...")
 				default:
 					sbbs.LogErr("An invalid unitTest argument was supplied.")
 					sbbs.LogInfo("Usage: ")
@@ -66,7 +72,7 @@ func registerUnitTestTargets() {
 				arg := "nosimd"
 				if len(cmdLineArgs) != 1 {
 					sbbs.LogInfo("Defaulting to non-simd unit tests")
-					sbbs.LogInfo("Available test targets: %v", testTargets)
+					sbbs.LogInfo("Available test targets: %v", unitTestTargets)
 				} else {
 					arg = cmdLineArgs[0]
 				}
@@ -99,10 +105,17 @@ func registerUnitTestTargets() {
 						"-gcflags", "-N", "-ldflags=-compressdwarf=false",
 						"-c", "./...",
 					)
+				case "randiter":
+					return sbbs.RunStdout(
+						ctxt, "go", "test",
+						"-tags=sbmap_randiter",
+						"-gcflags", "-N", "-ldflags=-compressdwarf=false",
+						"-c", "./...",
+					)
 				default:
 					sbbs.LogErr("An invalid unitTest argument was supplied.")
 					sbbs.LogInfo("Usage: ")
-					sbbs.LogInfo("\t./bs testexe %v", testTargets)
+					sbbs.LogInfo("\t./bs testexe %v", unitTestTargets)
 					sbbs.LogQuietInfo("Consider: Re-running with a valid unit test argument")
 					return sbbs.StopErr
 				}
@@ -0,0 +1,74 @@
+package sbmap
+
+import "hash/maphash"
+
+type (
+	// A map with string keys that can also be accessed with byte slices. The
+	// byte slice methods hash and compare the slice directly so looking up a
+	// key never converts it to a string. A string is only allocated when a
+	// new key is placed in the map with [BytesKeyMap.PutBytes].
+	//
+	// The byte slice methods produce the same hash as the string methods of
+	// the embedded [Map], so the two can be freely mixed. This only holds for
+	// maps created with [NewBytesKey].
+	BytesKeyMap[V any] struct {
+		Map[string, V]
+	}
+)
+
+// Creates a BytesKeyMap where V is the value type. [ComparableEqual] and
+// [ComparableHash] functions will be Used for string keys.
+func NewBytesKey[V any]() BytesKeyMap[V] {
+	return BytesKeyMap[V]{
+		Map: NewCustom[string, V](
+			_defaultInitialCap, ComparableEqual[string], ComparableHash[string](),
+		),
+	}
+}
+
+// Returns the same hash that [ComparableHash] returns for the string version of
+// the supplied bytes.
+func hashBytesKey(k []byte) uint64 {
+	return maphash.Bytes(_comparableSeed, k)
+}
+
+func (m *BytesKeyMap[V]) findBytes(k []byte) (uint64, int, bool) {
+	return m.findSlotHashed(
+		hashBytesKey(k),
+		// The compiler does not allocate when converting to a string only to
+		// compare it
+		func(other string) bool { return other == string(k) },
+	)
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned. No allocations are made.
+func (m *BytesKeyMap[V]) GetBytes(k []byte) (V, bool) {
+	if groupIdx, slotIdx, ok := m.findBytes(k); ok {
+		return m.groups[groupIdx].slots[slotIdx].value, true
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Places the supplied key, value pair in the map. If the key was already
+// present in the map the old value will be overwritten without allocating,
+// otherwise the key will be copied into a new string.
+func (m *BytesKeyMap[V]) PutBytes(k []byte, v V) {
+	if groupIdx, slotIdx, ok := m.findBytes(k); ok {
+		m.groups[groupIdx].slots[slotIdx].value = v
+		return
+	}
+	m.Put(string(k), v)
+}
+
+// Removes the supplied key and associated value from the map if it is present.
+// No allocations are made.
+func (m *BytesKeyMap[V]) RemoveBytes(k []byte) {
+	if groupIdx, slotIdx, ok := m.findBytes(k); ok {
+		m.tombstone(groupIdx, slotIdx)
+	}
+	m.shrinkIfSparse()
+}
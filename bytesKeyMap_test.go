@@ -0,0 +1,63 @@
+package sbmap
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestBytesKeyHashMatchesString(t *testing.T) {
+	hash := ComparableHash[string]()
+	r := rand.New(rand.NewSource(3))
+	for i := range 100 {
+		b := make([]byte, i)
+		r.Read(b)
+		sbtest.Eq(t, hash(string(b)), hashBytesKey(b))
+	}
+}
+
+func TestBytesKeyMap(t *testing.T) {
+	h := NewBytesKey[int]()
+	for i := range 1000 {
+		h.PutBytes([]byte(strconv.Itoa(i)), i)
+	}
+	sbtest.Eq(t, 1000, h.Len())
+
+	for i := range 1000 {
+		val, ok := h.Get(strconv.Itoa(i))
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+
+		h.Put(strconv.Itoa(i), i*2)
+		val, ok = h.GetBytes([]byte(strconv.Itoa(i)))
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i*2, val)
+	}
+	_, ok := h.GetBytes([]byte("1000"))
+	sbtest.False(t, ok)
+
+	h.PutBytes([]byte("1"), -1)
+	sbtest.Eq(t, 1000, h.Len())
+	val, _ := h.Get("1")
+	sbtest.Eq(t, -1, val)
+
+	for i := range 1000 {
+		h.RemoveBytes([]byte(strconv.Itoa(i)))
+	}
+	sbtest.Eq(t, 0, h.Len())
+	sbtest.Eq(t, _defaultInitialCap, len(h.groups))
+}
+
+func TestBytesKeyMapDoesNotAllocate(t *testing.T) {
+	h := NewBytesKey[int]()
+	k := []byte("some-key")
+	h.PutBytes(k, 1)
+
+	sbtest.Eq(t, 0, testing.AllocsPerRun(100, func() {
+		_, _ = h.GetBytes(k)
+		h.PutBytes(k, 2)
+		h.RemoveBytes([]byte("missing"))
+	}))
+}
@@ -0,0 +1,338 @@
+// A bounded cache that uses a [sbmap.Map] as its index. Supports LRU and LFU
+// eviction policies with either an entry count limit, a weight limit, or both.
+package cache
+
+import (
+	"container/heap"
+
+	sbmap "github.com/barbell-math/smoothbrain-hashmap"
+)
+
+type (
+	// The policy that is used to pick which entry to remove when the cache
+	// is full.
+	EvictionPolicy int
+
+	// The options that control the size and behavior of a [Cache].
+	Opts[K any, V any] struct {
+		// The eviction policy the cache will use. Defaults to [LRU].
+		Policy EvictionPolicy
+		// The maximum number of entries the cache will hold. Zero means the
+		// number of entries is not bounded.
+		MaxEntries int
+		// The maximum total weight of all entries in the cache, as reported by
+		// the Weight function. Zero means the weight is not bounded.
+		MaxWeight uint64
+		// The function used to calculate the weight of an entry. If nil every
+		// entry has a weight of one.
+		Weight func(k K, v V) uint64
+		// Called with the key and value of every entry that is evicted to
+		// make room for other entries. It is not called for entries that are
+		// explicitly removed.
+		OnEvict func(k K, v V)
+	}
+
+	// Hit and miss statistics for a [Cache]. Only [Cache.Get] updates the hit
+	// and miss counts.
+	Stats struct {
+		Hits      uint64
+		Misses    uint64
+		Evictions uint64
+	}
+
+	entry[K any, V any] struct {
+		key    K
+		value  V
+		weight uint64
+		// The number of times the entry has been accessed. Only Used by LFU.
+		freq uint64
+		// The value of the caches clock the last time the entry was accessed.
+		// Only Used by LFU to break ties between entries with equal freq.
+		tick uint64
+		// The entries position in the heap. Only Used by LFU.
+		pos int
+		// Links for the recency list. Only Used by LRU.
+		prev int
+		next int
+	}
+
+	Cache[K any, V any] struct {
+		index   sbmap.Map[K, int]
+		entries []entry[K, V]
+		free    []int
+		// The most and least recently Used entries. Only Used by LRU.
+		head int
+		tail int
+		// A min heap of entry indexes. Only Used by LFU.
+		lfu    []int
+		tick   uint64
+		weight uint64
+		opts   Opts[K, V]
+		stats  Stats
+	}
+
+	lfuHeap[K any, V any] struct {
+		c *Cache[K, V]
+	}
+)
+
+const (
+	// Evicts the least recently Used entry.
+	LRU EvictionPolicy = iota
+	// Evicts the least frequently Used entry. Ties are broken by evicting the
+	// least recently Used entry.
+	LFU
+)
+
+const nilIdx = -1
+
+// Creates a Cache where K is the key type and V is the value type.
+// [sbmap.ComparableEqual] and [sbmap.ComparableHash] will be Used by the
+// underlying index.
+func New[K comparable, V any](opts Opts[K, V]) Cache[K, V] {
+	return NewCustom(opts, sbmap.ComparableEqual[K], sbmap.ComparableHash[K]())
+}
+
+// Creates a Cache where K is the key type and V is the value type. The supplied
+// `eq` and `hash` functions will be Used by the underlying index. Refer to
+// [sbmap.NewCustom] for the requirements of the `eq` and `hash` functions.
+func NewCustom[K any, V any](
+	opts Opts[K, V],
+	eq func(l K, r K) bool,
+	hash func(v K) uint64,
+) Cache[K, V] {
+	return Cache[K, V]{
+		index: sbmap.NewCustom[K, int](1, eq, hash),
+		head:  nilIdx,
+		tail:  nilIdx,
+		opts:  opts,
+	}
+}
+
+// Returns the number of entries in the cache.
+func (c *Cache[K, V]) Len() int {
+	return c.index.Len()
+}
+
+// Returns the total weight of all entries in the cache.
+func (c *Cache[K, V]) Weight() uint64 {
+	return c.weight
+}
+
+// Returns the hit, miss, and eviction statistics for the cache.
+func (c *Cache[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// entry is marked as Used, counted as a hit, and the boolean return value will
+// be true. If the key is not found it is counted as a miss and the boolean
+// return value will be false.
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	idx, ok := c.index.Get(k)
+	if !ok {
+		c.stats.Misses++
+		var tmp V
+		return tmp, false
+	}
+	c.stats.Hits++
+	c.touch(idx)
+	return c.entries[idx].value, true
+}
+
+// Gets the value that is related to the supplied key without marking the entry
+// as Used and without updating the caches statistics.
+func (c *Cache[K, V]) Peek(k K) (V, bool) {
+	idx, ok := c.index.Get(k)
+	if !ok {
+		var tmp V
+		return tmp, false
+	}
+	return c.entries[idx].value, true
+}
+
+// Places the supplied key, value pair in the cache and marks it as Used. If the
+// key was already present the old value will be overwritten. Entries will be
+// evicted as necessary to keep the cache within its bounds, which may include
+// the newly placed entry if it alone exceeds the max weight.
+func (c *Cache[K, V]) Put(k K, v V) {
+	w := c.weightOf(k, v)
+	if idx, ok := c.index.Get(k); ok {
+		c.weight = c.weight - c.entries[idx].weight + w
+		c.entries[idx].value = v
+		c.entries[idx].weight = w
+		c.touch(idx)
+		c.evict()
+		return
+	}
+
+	// Make room before placing the new entry so that it is not considered for
+	// eviction, otherwise LFU would always evict the entry that was just placed.
+	for c.index.Len() > 0 && c.overBounds(1, w) {
+		c.evictOne()
+	}
+
+	var idx int
+	if l := len(c.free); l > 0 {
+		idx = c.free[l-1]
+		c.free = c.free[:l-1]
+	} else {
+		idx = len(c.entries)
+		c.entries = append(c.entries, entry[K, V]{})
+	}
+	c.entries[idx] = entry[K, V]{key: k, value: v, weight: w}
+	c.weight += w
+	c.index.Put(k, idx)
+	c.link(idx)
+	c.evict()
+}
+
+// Removes the supplied key and associated value from the cache if it is
+// present. The eviction callback is not called.
+func (c *Cache[K, V]) Remove(k K) {
+	if idx, ok := c.index.Get(k); ok {
+		c.delete(idx)
+	}
+}
+
+// Changes the bounds of the cache, evicting entries as necessary to fit within
+// the new bounds. Zero means the corresponding bound is removed.
+func (c *Cache[K, V]) Resize(maxEntries int, maxWeight uint64) {
+	c.opts.MaxEntries = maxEntries
+	c.opts.MaxWeight = maxWeight
+	c.evict()
+}
+
+func (c *Cache[K, V]) weightOf(k K, v V) uint64 {
+	if c.opts.Weight == nil {
+		return 1
+	}
+	return c.opts.Weight(k, v)
+}
+
+// Returns true if adding the supplied number of entries and weight would put the
+// cache over its bounds.
+func (c *Cache[K, V]) overBounds(entries int, weight uint64) bool {
+	return (c.opts.MaxEntries > 0 && c.index.Len()+entries > c.opts.MaxEntries) ||
+		(c.opts.MaxWeight > 0 && c.weight+weight > c.opts.MaxWeight)
+}
+
+func (c *Cache[K, V]) evict() {
+	for c.overBounds(0, 0) {
+		c.evictOne()
+	}
+}
+
+func (c *Cache[K, V]) evictOne() {
+	var idx int
+	switch c.opts.Policy {
+	case LFU:
+		idx = c.lfu[0]
+	default:
+		idx = c.tail
+	}
+	e := c.entries[idx]
+	c.delete(idx)
+	c.stats.Evictions++
+	if c.opts.OnEvict != nil {
+		c.opts.OnEvict(e.key, e.value)
+	}
+}
+
+// Adds a newly placed entry to the eviction bookkeeping.
+func (c *Cache[K, V]) link(idx int) {
+	c.tick++
+	switch c.opts.Policy {
+	case LFU:
+		c.entries[idx].freq = 1
+		c.entries[idx].tick = c.tick
+		heap.Push(lfuHeap[K, V]{c}, idx)
+	default:
+		c.entries[idx].prev = nilIdx
+		c.entries[idx].next = c.head
+		if c.head != nilIdx {
+			c.entries[c.head].prev = idx
+		}
+		c.head = idx
+		if c.tail == nilIdx {
+			c.tail = idx
+		}
+	}
+}
+
+// Removes an entry from the eviction bookkeeping.
+func (c *Cache[K, V]) unlink(idx int) {
+	switch c.opts.Policy {
+	case LFU:
+		heap.Remove(lfuHeap[K, V]{c}, c.entries[idx].pos)
+	default:
+		e := &c.entries[idx]
+		if e.prev != nilIdx {
+			c.entries[e.prev].next = e.next
+		} else {
+			c.head = e.next
+		}
+		if e.next != nilIdx {
+			c.entries[e.next].prev = e.prev
+		} else {
+			c.tail = e.prev
+		}
+	}
+}
+
+// Marks an existing entry as Used.
+func (c *Cache[K, V]) touch(idx int) {
+	c.tick++
+	switch c.opts.Policy {
+	case LFU:
+		c.entries[idx].freq++
+		c.entries[idx].tick = c.tick
+		heap.Fix(lfuHeap[K, V]{c}, c.entries[idx].pos)
+	default:
+		if c.head == idx {
+			return
+		}
+		c.unlink(idx)
+		c.link(idx)
+	}
+}
+
+func (c *Cache[K, V]) delete(idx int) {
+	c.unlink(idx)
+	c.index.Remove(c.entries[idx].key)
+	c.weight -= c.entries[idx].weight
+	c.entries[idx] = entry[K, V]{}
+	c.free = append(c.free, idx)
+}
+
+func (h lfuHeap[K, V]) Len() int {
+	return len(h.c.lfu)
+}
+
+func (h lfuHeap[K, V]) Less(i int, j int) bool {
+	l := &h.c.entries[h.c.lfu[i]]
+	r := &h.c.entries[h.c.lfu[j]]
+	if l.freq != r.freq {
+		return l.freq < r.freq
+	}
+	return l.tick < r.tick
+}
+
+func (h lfuHeap[K, V]) Swap(i int, j int) {
+	h.c.lfu[i], h.c.lfu[j] = h.c.lfu[j], h.c.lfu[i]
+	h.c.entries[h.c.lfu[i]].pos = i
+	h.c.entries[h.c.lfu[j]].pos = j
+}
+
+func (h lfuHeap[K, V]) Push(x any) {
+	idx := x.(int)
+	h.c.entries[idx].pos = len(h.c.lfu)
+	h.c.lfu = append(h.c.lfu, idx)
+}
+
+func (h lfuHeap[K, V]) Pop() any {
+	l := len(h.c.lfu)
+	rv := h.c.lfu[l-1]
+	h.c.lfu = h.c.lfu[:l-1]
+	return rv
+}
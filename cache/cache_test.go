@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestLRUEviction(t *testing.T) {
+	evicted := []int{}
+	c := New(Opts[int, int]{
+		Policy:     LRU,
+		MaxEntries: 3,
+		OnEvict:    func(k int, v int) { evicted = append(evicted, k) },
+	})
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+	sbtest.Eq(t, 3, c.Len())
+
+	_, ok := c.Get(1)
+	sbtest.True(t, ok)
+	c.Put(4, 4)
+	sbtest.Eq(t, 3, c.Len())
+	sbtest.Eq(t, []int{2}, evicted)
+
+	_, ok = c.Get(2)
+	sbtest.False(t, ok)
+	c.Put(5, 5)
+	sbtest.Eq(t, []int{2, 3}, evicted)
+
+	for _, k := range []int{1, 4, 5} {
+		v, ok := c.Peek(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, k, v)
+	}
+}
+
+func TestLFUEviction(t *testing.T) {
+	evicted := []int{}
+	c := New(Opts[int, int]{
+		Policy:     LFU,
+		MaxEntries: 3,
+		OnEvict:    func(k int, v int) { evicted = append(evicted, k) },
+	})
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Get(3)
+	c.Get(3)
+
+	c.Put(4, 4)
+	sbtest.Eq(t, []int{2}, evicted)
+	c.Put(5, 5)
+	sbtest.Eq(t, []int{2, 4}, evicted)
+
+	_, ok := c.Peek(1)
+	sbtest.True(t, ok)
+	_, ok = c.Peek(3)
+	sbtest.True(t, ok)
+	_, ok = c.Peek(5)
+	sbtest.True(t, ok)
+}
+
+func TestPeekDoesNotTouch(t *testing.T) {
+	c := New(Opts[int, int]{MaxEntries: 2})
+
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Peek(1)
+	c.Put(3, 3)
+
+	_, ok := c.Peek(1)
+	sbtest.False(t, ok)
+	sbtest.Eq(t, Stats{Evictions: 1}, c.Stats())
+}
+
+func TestMaxWeight(t *testing.T) {
+	c := New(Opts[string, string]{
+		MaxWeight: 10,
+		Weight:    func(k string, v string) uint64 { return uint64(len(v)) },
+	})
+
+	c.Put("a", "aaaa")
+	c.Put("b", "bbbb")
+	sbtest.Eq(t, uint64(8), c.Weight())
+
+	c.Put("c", "cccc")
+	sbtest.Eq(t, 2, c.Len())
+	sbtest.Eq(t, uint64(8), c.Weight())
+	_, ok := c.Peek("a")
+	sbtest.False(t, ok)
+
+	c.Put("b", "b")
+	sbtest.Eq(t, uint64(5), c.Weight())
+
+	c.Put("d", "ddddddddddd")
+	sbtest.Eq(t, 0, c.Len())
+	sbtest.Eq(t, uint64(0), c.Weight())
+}
+
+func TestRemoveAndResize(t *testing.T) {
+	evicted := 0
+	c := New(Opts[int, int]{
+		OnEvict: func(k int, v int) { evicted++ },
+	})
+
+	for i := range 100 {
+		c.Put(i, i)
+	}
+	sbtest.Eq(t, 100, c.Len())
+
+	c.Remove(0)
+	c.Remove(0)
+	sbtest.Eq(t, 99, c.Len())
+	sbtest.Eq(t, 0, evicted)
+
+	c.Resize(10, 0)
+	sbtest.Eq(t, 10, c.Len())
+	sbtest.Eq(t, 89, evicted)
+	for i := 90; i < 100; i++ {
+		v, ok := c.Peek(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, v)
+	}
+
+	for i := range 100 {
+		c.Put(i, i)
+	}
+	sbtest.Eq(t, 10, c.Len())
+}
+
+func TestStats(t *testing.T) {
+	c := New(Opts[int, int]{MaxEntries: 1})
+
+	c.Put(1, 1)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Put(2, 2)
+	sbtest.Eq(t, Stats{Hits: 2, Misses: 1, Evictions: 1}, c.Stats())
+}
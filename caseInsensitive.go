@@ -0,0 +1,140 @@
+package sbmap
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand/v2"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The seed Used by the case insensitive hash functions. It is random so that
+// keys that come from untrusted sources, such as HTTP headers, cannot be chosen
+// to collide.
+var _foldSeed = rand.Uint64()
+
+const (
+	_ones  = 0x0101010101010101
+	_highs = 0x8080808080808080
+)
+
+// Creates a Map with string keys that are compared ignoring ASCII case, so
+// "Content-Type" and "content-type" are the same key. Non-ASCII characters are
+// compared exactly. Keys are folded as they are hashed and compared so no
+// allocations are made. The first version of a key that is put in the map is
+// the version that is stored.
+func NewCaseInsensitive[V any]() Map[string, V] {
+	return NewCustom[string, V](
+		_defaultInitialCap, equalFoldASCII, hashFoldASCII,
+	)
+}
+
+// Creates a Map with string keys that are compared using Unicode simple case
+// folding, the same rules that [strings.EqualFold] uses. Keys are folded as
+// they are hashed and compared so no allocations are made. The first version
+// of a key that is put in the map is the version that is stored.
+func NewUnicodeFold[V any]() Map[string, V] {
+	return NewCustom[string, V](
+		_defaultInitialCap, strings.EqualFold, hashFoldUnicode,
+	)
+}
+
+// Lower cases all ASCII letters in the supplied word, eight bytes at a time.
+// Bytes that are not upper case ASCII letters are left as is.
+func foldASCIIWord(w uint64) uint64 {
+	// The high bit is cleared so the additions below cannot carry between
+	// bytes, bytes that had the high bit set are not ASCII and are excluded
+	// at the end.
+	low := w &^ _highs
+	geA := (low + (0x80-'A')*_ones) & _highs
+	gtZ := (low + (0x80-'Z'-1)*_ones) & _highs
+	isUpper := geA &^ gtZ &^ w
+	// Moves the high bit of each upper case byte to 0x20, the ASCII case bit
+	return w | (isUpper >> 2)
+}
+
+func hashFoldASCII(v string) uint64 {
+	rv := _foldSeed ^ uint64(len(v))
+	var tail [16]byte
+	for len(v) > 0 {
+		var l, r uint64
+		if len(v) >= 16 {
+			l = stringUint64(v[:8])
+			r = stringUint64(v[8:16])
+			v = v[16:]
+		} else {
+			tail = [16]byte{}
+			copy(tail[:], v)
+			l = binary.LittleEndian.Uint64(tail[:8])
+			r = binary.LittleEndian.Uint64(tail[8:])
+			v = v[len(v):]
+		}
+		hi, lo := bits.Mul64(
+			foldASCIIWord(l)^0xa0761d6478bd642f, foldASCIIWord(r)^rv,
+		)
+		rv = hi ^ lo
+	}
+	return mix64(rv, _foldSeed)
+}
+
+// Reads the first eight bytes of the string as a little endian uint64. The
+// compiler combines the byte loads into a single load.
+func stringUint64(v string) uint64 {
+	_ = v[7]
+	return uint64(v[0]) | uint64(v[1])<<8 | uint64(v[2])<<16 | uint64(v[3])<<24 |
+		uint64(v[4])<<32 | uint64(v[5])<<40 | uint64(v[6])<<48 | uint64(v[7])<<56
+}
+
+func equalFoldASCII(l string, r string) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := 0; i < len(l); i++ {
+		if l[i] == r[i] {
+			continue
+		}
+		lc, rc := l[i]|0x20, r[i]|0x20
+		if lc != rc || lc < 'a' || lc > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the smallest rune that is equivalent to the supplied rune under
+// simple case folding. All runes that are equal according to
+// [strings.EqualFold] have the same canonical rune.
+func canonicalFold(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	rv := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		rv = min(rv, f)
+	}
+	return rv
+}
+
+func hashFoldUnicode(v string) uint64 {
+	rv := _foldSeed
+	var word uint64
+	n := 0
+	for _, r := range v {
+		word = word<<32 | uint64(canonicalFold(r))
+		n++
+		if n%2 == 0 {
+			hi, lo := bits.Mul64(word^0xa0761d6478bd642f, rv^0xe7037ed1a0b428db)
+			rv = hi ^ lo
+			word = 0
+		}
+	}
+	if n%2 == 1 {
+		hi, lo := bits.Mul64(word^0xa0761d6478bd642f, rv^0xe7037ed1a0b428db)
+		rv = hi ^ lo
+	}
+	return mix64(rv^uint64(n), _foldSeed)
+}
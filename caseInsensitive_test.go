@@ -0,0 +1,103 @@
+package sbmap
+
+import (
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestFoldASCIIWord(t *testing.T) {
+	in := "@AZ[`az{"
+	sbtest.Eq(t, "@az[`az{", string(uint64Bytes(foldASCIIWord(stringUint64(in)))))
+	in = "\xc1\xdaMiXeD!"
+	sbtest.Eq(t, "\xc1\xdamixed!", string(uint64Bytes(foldASCIIWord(stringUint64(in)))))
+}
+
+func uint64Bytes(v uint64) []byte {
+	rv := make([]byte, 8)
+	for i := range rv {
+		rv[i] = byte(v >> (8 * i))
+	}
+	return rv
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	h := NewCaseInsensitive[int]()
+	h.Put("Content-Type", 1)
+	h.Put("content-type", 2)
+	h.Put("CONTENT-LENGTH", 3)
+	h.Put("X-A-Very-Long-Header-Name-That-Spans-Words", 4)
+	sbtest.Eq(t, 3, h.Len())
+
+	val, ok := h.Get("CoNtEnT-tYpE")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+	val, ok = h.Get("x-a-very-long-header-name-that-spans-words")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 4, val)
+	_, ok = h.Get("Content-Typ")
+	sbtest.False(t, ok)
+	// Only ASCII letters are folded
+	_, ok = h.Get("content\x0dtype")
+	sbtest.False(t, ok)
+	h.Put("ä", 5)
+	_, ok = h.Get("Ä")
+	sbtest.False(t, ok)
+
+	h.Remove("content-length")
+	sbtest.Eq(t, 3, h.Len())
+
+	for i := range 1000 {
+		h.Put(strings.Repeat("a", i), i)
+	}
+	for i := range 1000 {
+		val, ok := h.Get(strings.Repeat("A", i))
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+func TestUnicodeFold(t *testing.T) {
+	h := NewUnicodeFold[int]()
+	h.Put("straße", 1)
+	h.Put("ΣΊΣΥΦΟΣ", 2)
+	h.Put("kelvin", 3)
+	h.Put("STRASSE", 4)
+	sbtest.Eq(t, 4, h.Len())
+
+	val, ok := h.Get("STRAßE")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 1, val)
+	// Final and non-final sigma fold to the same rune
+	val, ok = h.Get("σίσυφος")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+	// U+212A KELVIN SIGN folds to k
+	val, ok = h.Get("KELVIN")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 3, val)
+	_, ok = h.Get("strase")
+	sbtest.False(t, ok)
+}
+
+func TestFoldHashesAreConsistentWithEquality(t *testing.T) {
+	pairs := [][2]string{
+		{"", ""},
+		{"a", "A"},
+		{"hello world, this is long", "HELLO WORLD, THIS IS LONG"},
+		{"ſ", "S"},
+		{"K", "k"},
+		{"ς", "Σ"},
+	}
+	for _, p := range pairs {
+		sbtest.True(t, strings.EqualFold(p[0], p[1]))
+		sbtest.Eq(t, hashFoldUnicode(p[0]), hashFoldUnicode(p[1]))
+		if equalFoldASCII(p[0], p[1]) {
+			sbtest.Eq(t, hashFoldASCII(p[0]), hashFoldASCII(p[1]))
+		}
+	}
+	sbtest.True(t, hashFoldASCII("ab") != hashFoldASCII("ba"))
+	sbtest.True(t, hashFoldUnicode("ab") != hashFoldUnicode("ba"))
+	sbtest.True(t, hashFoldUnicode("a") != hashFoldUnicode("a\x00"))
+}
@@ -0,0 +1,81 @@
+package sbmap
+
+import "iter"
+
+type (
+	// A value that is different between the two maps given to [Diff].
+	Change[V any] struct {
+		Old V
+		New V
+	}
+)
+
+// Compares the key, value pairs of `a` and `b`, treating `a` as the old version
+// and `b` as the new version. Values are compared with `eqV`. Three iterators
+// are returned:
+//   - added: the key, value pairs in `b` whose keys are not in `a`
+//   - removed: the key, value pairs in `a` whose keys are not in `b`
+//   - changed: the keys that are in both maps with different values, along
+//     with the old and new values
+//
+// The iterators are lazy, the maps are compared as the iterators are Used.
+// Neither map can be modified while any of the iterators are being Used.
+func Diff[K any, V any](
+	a *Map[K, V],
+	b *Map[K, V],
+	eqV func(l V, r V) bool,
+) (added iter.Seq2[K, V], removed iter.Seq2[K, V], changed iter.Seq2[K, Change[V]]) {
+	added = missingFrom(b, a)
+	removed = missingFrom(a, b)
+	changed = func(yield func(k K, c Change[V]) bool) {
+		for s := range b.liveSlots() {
+			if old, ok := a.Get(s.key); ok && !eqV(old, s.value) &&
+				!yield(s.key, Change[V]{Old: old, New: s.value}) {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Iterates over the key, value pairs in `m` whose keys are not in `other`.
+func missingFrom[K any, V any](m *Map[K, V], other *Map[K, V]) iter.Seq2[K, V] {
+	return func(yield func(k K, v V) bool) {
+		for s := range m.liveSlots() {
+			if _, _, ok := other.findSlot(s.key); !ok && !yield(s.key, s.value) {
+				return
+			}
+		}
+	}
+}
+
+// Places all of the key, value pairs from `src` in `dst`. When a key is in
+// both maps the value placed in `dst` is the result of calling `resolve` with
+// the key and both values. If `resolve` is nil the value from `src` is Used.
+// `dst` is grown once up front to fit all of the keys from `src` that it does
+// not have. `src` is not changed.
+func Merge[K any, V any](
+	dst *Map[K, V],
+	src *Map[K, V],
+	resolve func(k K, dst V, src V) V,
+) {
+	newKeys := 0
+	for range missingFrom(src, dst) {
+		newKeys++
+	}
+	dst.Reserve(newKeys)
+
+	for s := range src.liveSlots() {
+		groupIdx, slotIdx, ok := dst.findSlot(s.key)
+		if !ok {
+			dst.Put(s.key, s.value)
+			continue
+		}
+		if resolve == nil {
+			dst.groups[groupIdx].slots[slotIdx].value = s.value
+		} else {
+			v := &dst.groups[groupIdx].slots[slotIdx].value
+			*v = resolve(s.key, *v, s.value)
+		}
+	}
+}
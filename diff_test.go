@@ -0,0 +1,92 @@
+package sbmap
+
+import (
+	"maps"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestDiff(t *testing.T) {
+	a := New[int, string]()
+	b := New[int, string]()
+	for i := range 100 {
+		a.Put(i, "a")
+	}
+	for i := 50; i < 150; i++ {
+		if i%10 == 0 {
+			b.Put(i, "b")
+		} else {
+			b.Put(i, "a")
+		}
+	}
+
+	added, removed, changed := Diff(&a, &b, ComparableEqual[string])
+	expAdded := map[int]string{}
+	for i := 100; i < 150; i++ {
+		expAdded[i], _ = b.Get(i)
+	}
+	sbtest.Eq(t, expAdded, maps.Collect(added))
+
+	expRemoved := map[int]string{}
+	for i := range 50 {
+		expRemoved[i] = "a"
+	}
+	sbtest.Eq(t, expRemoved, maps.Collect(removed))
+
+	expChanged := map[int]Change[string]{}
+	for i := 50; i < 100; i += 10 {
+		expChanged[i] = Change[string]{Old: "a", New: "b"}
+	}
+	sbtest.Eq(t, expChanged, maps.Collect(changed))
+
+	added, removed, changed = Diff(&a, &a, ComparableEqual[string])
+	sbtest.Eq(t, 0, len(maps.Collect(added)))
+	sbtest.Eq(t, 0, len(maps.Collect(removed)))
+	sbtest.Eq(t, 0, len(maps.Collect(changed)))
+}
+
+func TestMerge(t *testing.T) {
+	dst := New[int, int]()
+	src := New[int, int]()
+	for i := range 100 {
+		dst.Put(i, i)
+		src.Put(i+50, 1000)
+	}
+
+	Merge(&dst, &src, func(k int, d int, s int) int { return d + s })
+	sbtest.Eq(t, 150, dst.Len())
+	sbtest.Eq(t, 100, src.Len())
+	for i := range 150 {
+		val, ok := dst.Get(i)
+		sbtest.True(t, ok)
+		switch {
+		case i < 50:
+			sbtest.Eq(t, i, val)
+		case i < 100:
+			sbtest.Eq(t, i+1000, val)
+		default:
+			sbtest.Eq(t, 1000, val)
+		}
+	}
+
+	Merge(&dst, &src, nil)
+	for i := 50; i < 150; i++ {
+		val, _ := dst.Get(i)
+		sbtest.Eq(t, 1000, val)
+	}
+}
+
+func TestMergeReservesOnce(t *testing.T) {
+	dst := New[int, int]()
+	src := New[int, int]()
+	for i := range 10000 {
+		src.Put(i, i)
+	}
+	exp := New[int, int]()
+	exp.Reserve(10000)
+
+	Merge(&dst, &src, nil)
+	sbtest.Eq(t, 10000, dst.Len())
+	sbtest.Eq(t, cap(exp.groups), cap(dst.groups))
+}
@@ -0,0 +1,16 @@
+package sbmap
+
+import "errors"
+
+var (
+	// Returned when decoding into a Map that was not created with one of the
+	// constructors. The decoded values cannot be placed in the map because it
+	// does not have hash or equality functions.
+	ErrUninitializedMap = errors.New(
+		"sbmap: cannot decode into a map that was not created with a constructor",
+	)
+
+	errMismatchedGobLens = errors.New(
+		"sbmap: gob data has a different number of keys and values",
+	)
+)
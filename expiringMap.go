@@ -0,0 +1,158 @@
+package sbmap
+
+import (
+	"iter"
+	"time"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+type (
+	// The source of the current time for an [ExpiringMap]. Supplying a custom
+	// clock allows expiration to be controlled without sleeping, which is
+	// mostly useful for tests.
+	Clock interface {
+		Now() time.Time
+	}
+
+	systemClock struct{}
+
+	expiringValue[V any] struct {
+		value V
+		// The zero time means the value never expires.
+		expires time.Time
+	}
+
+	// A map where every entry can be given a time to live. Expired entries are
+	// never returned and are removed lazily as they are found or all at once
+	// with [ExpiringMap.Sweep].
+	ExpiringMap[K any, V any] struct {
+		m     Map[K, expiringValue[V]]
+		clock Clock
+	}
+)
+
+func (_ systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Creates an ExpiringMap where K is the key type and V is the value type.
+// [ComparableEqual] and [ComparableHash] functions will be Used by the returned
+// map. If `clock` is nil the system clock will be Used.
+func NewExpiring[K comparable, V any](clock Clock) ExpiringMap[K, V] {
+	return NewCustomExpiring[K, V](
+		_defaultInitialCap, ComparableEqual[K], ComparableHash[K](), clock,
+	)
+}
+
+// Creates an ExpiringMap where K is the key type and V is the value type with a
+// capacity of `_cap`. The supplied `eq` and `hash` functions will be Used by
+// the map, refer to [NewCustom] for their requirements. If `clock` is nil the
+// system clock will be Used.
+func NewCustomExpiring[K any, V any](
+	_cap int,
+	eq func(l K, r K) bool,
+	hash func(v K) uint64,
+	clock Clock,
+) ExpiringMap[K, V] {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return ExpiringMap[K, V]{
+		m:     NewCustom[K, expiringValue[V]](_cap, eq, hash),
+		clock: clock,
+	}
+}
+
+// Returns the number of elements in the map. Expired elements that have not
+// been removed yet are included in the count, call [ExpiringMap.Sweep] first
+// for an exact count.
+func (m *ExpiringMap[K, V]) Len() int {
+	return m.m.Len()
+}
+
+func (m *ExpiringMap[K, V]) expired(v *expiringValue[V], now time.Time) bool {
+	return !v.expires.IsZero() && !now.Before(v.expires)
+}
+
+// Gets the value that is related to the supplied key. Expired values are
+// treated as if they are not present and are removed from the map when found.
+func (m *ExpiringMap[K, V]) Get(k K) (V, bool) {
+	groupIdx, slotIdx, ok := m.m.findSlot(k)
+	if !ok {
+		var tmp V
+		return tmp, false
+	}
+
+	v := &m.m.groups[groupIdx].slots[slotIdx].value
+	if m.expired(v, m.clock.Now()) {
+		m.m.tombstone(groupIdx, slotIdx)
+		m.m.shrinkIfSparse()
+		var tmp V
+		return tmp, false
+	}
+	return v.value, true
+}
+
+// Places the supplied key, value pair in the map. The value will expire once
+// `ttl` has passed. A `ttl` that is less than or equal to zero means the value
+// will never expire. If the key was already present in the map the old value
+// and expiration time will be overwritten.
+func (m *ExpiringMap[K, V]) Put(k K, v V, ttl time.Duration) {
+	ev := expiringValue[V]{value: v}
+	if ttl > 0 {
+		ev.expires = m.clock.Now().Add(ttl)
+	}
+	m.m.Put(k, ev)
+}
+
+// Removes the supplied key and associated value from the map if it is present.
+func (m *ExpiringMap[K, V]) Remove(k K) {
+	m.m.Remove(k)
+}
+
+// Removes all expired values from the map, returning the number of values that
+// were removed.
+func (m *ExpiringMap[K, V]) Sweep() int {
+	now := m.clock.Now()
+	cntr := 0
+	for i := range m.m.groups {
+		for j := range m.m.groups[i].slots {
+			if m.m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
+				m.expired(&m.m.groups[i].slots[j].value, now) {
+				m.m.tombstone(uint64(i), j)
+				cntr++
+			}
+		}
+	}
+	if cntr > 0 {
+		m.m.shrinkIfSparse()
+	}
+	return cntr
+}
+
+// Iterates over all of the keys in the map that have not expired. Uses the
+// stdlib `iter` package so this function can be Used in a standard `for` loop.
+func (m *ExpiringMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		now := m.clock.Now()
+		for s := range m.m.liveSlots() {
+			if !m.expired(&s.value, now) && !yield(s.key) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over all of the values in the map that have not expired. Uses the
+// stdlib `iter` package so this function can be Used in a standard `for` loop.
+func (m *ExpiringMap[K, V]) Vals() iter.Seq[V] {
+	return func(yield func(v V) bool) {
+		now := m.clock.Now()
+		for s := range m.m.liveSlots() {
+			if !m.expired(&s.value, now) && !yield(s.value.value) {
+				return
+			}
+		}
+	}
+}
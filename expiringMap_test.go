@@ -0,0 +1,107 @@
+package sbmap
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestExpiringMapGet(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	h := NewExpiring[int, int](clock)
+
+	h.Put(1, 1, time.Second)
+	h.Put(2, 2, 2*time.Second)
+	h.Put(3, 3, 0)
+	sbtest.Eq(t, 3, h.Len())
+
+	val, ok := h.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 1, val)
+
+	clock.now = clock.now.Add(time.Second)
+	_, ok = h.Get(1)
+	sbtest.False(t, ok)
+	sbtest.Eq(t, 2, h.Len())
+	val, ok = h.Get(2)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+
+	clock.now = clock.now.Add(time.Hour)
+	_, ok = h.Get(2)
+	sbtest.False(t, ok)
+	val, ok = h.Get(3)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 3, val)
+	sbtest.Eq(t, 1, h.Len())
+}
+
+func TestExpiringMapPutRefreshesTTL(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	h := NewExpiring[int, int](clock)
+
+	h.Put(1, 1, time.Second)
+	clock.now = clock.now.Add(time.Second)
+	h.Put(1, 2, time.Second)
+
+	val, ok := h.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+	sbtest.Eq(t, 1, h.Len())
+}
+
+func TestExpiringMapSweep(t *testing.T) {
+	clock := &testClock{now: time.Unix(0, 0)}
+	h := NewExpiring[int, int](clock)
+
+	for i := range 1000 {
+		h.Put(i, i, time.Duration(i%2+1)*time.Second)
+	}
+	sbtest.Eq(t, 1000, h.Len())
+	sbtest.Eq(t, 0, h.Sweep())
+
+	clock.now = clock.now.Add(time.Second)
+	keys := slices.Collect(h.Keys())
+	sbtest.Eq(t, 500, len(keys))
+	for _, k := range keys {
+		sbtest.Eq(t, 1, k%2)
+	}
+	sbtest.Eq(t, 1000, h.Len())
+
+	sbtest.Eq(t, 500, h.Sweep())
+	sbtest.Eq(t, 500, h.Len())
+	for i := range 1000 {
+		val, ok := h.Get(i)
+		sbtest.Eq(t, i%2 == 1, ok)
+		if ok {
+			sbtest.Eq(t, i, val)
+		}
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	sbtest.Eq(t, 0, len(slices.Collect(h.Vals())))
+	sbtest.Eq(t, 500, h.Sweep())
+	sbtest.Eq(t, 0, h.Len())
+}
+
+func TestExpiringMapRemove(t *testing.T) {
+	h := NewExpiring[int, int](nil)
+
+	h.Put(1, 1, time.Hour)
+	h.Put(2, 2, 0)
+	h.Remove(1)
+	sbtest.Eq(t, 1, h.Len())
+	_, ok := h.Get(1)
+	sbtest.False(t, ok)
+	sbtest.SlicesMatchUnordered(t, []int{2}, slices.Collect(h.Vals()))
+}
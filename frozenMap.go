@@ -0,0 +1,84 @@
+package sbmap
+
+import (
+	"iter"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+type (
+	// A read only map created by [Map.Freeze]. A FrozenMap has no methods that
+	// modify it so it cannot be mutated once created, and it is safe for
+	// concurrent reads.
+	FrozenMap[K any, V any] struct {
+		m Map[K, V]
+	}
+)
+
+var (
+	// A value between 0 and 100 that determines how full a frozen map can be.
+	// This is lower than the grow factor of a map because a frozen map never
+	// grows, and a lower load means shorter probe sequences for Get.
+	_frozenLoadFactor = 50
+)
+
+// Creates a [FrozenMap] holding all of the key, value pairs in the map. The
+// frozen map uses the smallest table that keeps it at or below the frozen
+// load factor, and holds no deleted slots. The map is copied so it can still
+// be Used after it is frozen.
+func (m *Map[K, V]) Freeze() FrozenMap[K, V] {
+	newCap := 1
+	for m.Len()*100 > _frozenLoadFactor*newCap*slotprobes.GroupSize {
+		newCap <<= _sliceGrowthFactor
+	}
+
+	rv := Map[K, V]{
+		groups:         make([]group[K, V], newCap, newCap),
+		len:            0,
+		eq:             m.eq,
+		hash:           m.hash,
+		seed:           m.seed,
+		comparableHash: m.comparableHash,
+	}
+	for s := range m.liveSlots() {
+		rv.Put(s.key, s.value)
+	}
+	return FrozenMap[K, V]{m: rv}
+}
+
+// Creates a new [Map] holding all of the key, value pairs in the frozen map.
+// The frozen map is not changed.
+func (f *FrozenMap[K, V]) Thaw() Map[K, V] {
+	return *f.m.Copy()
+}
+
+// Returns the number of elements in the map.
+func (f *FrozenMap[K, V]) Len() int {
+	return f.m.Len()
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned.
+func (f *FrozenMap[K, V]) Get(k K) (V, bool) {
+	return f.m.Get(k)
+}
+
+// Iterates over all of the keys in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (f *FrozenMap[K, V]) Keys() iter.Seq[K] {
+	return f.m.Keys()
+}
+
+// Iterates over all of the key, value pairs in the map. Uses the stdlib `iter`
+// package so this function can be Used in a standard `for` loop.
+func (f *FrozenMap[K, V]) All() iter.Seq2[K, V] {
+	return f.m.All()
+}
+
+// Iterates over all of the values in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (f *FrozenMap[K, V]) Vals() iter.Seq[V] {
+	return f.m.Vals()
+}
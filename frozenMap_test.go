@@ -0,0 +1,72 @@
+package sbmap
+
+import (
+	"testing"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestFreeze(t *testing.T) {
+	h := New[int32, int32]()
+	for i := range int32(10000) {
+		h.Put(i, i)
+	}
+	for i := int32(100); i < 10000; i++ {
+		h.Remove(i)
+	}
+	f := h.Freeze()
+	sbtest.Eq(t, 100, f.Len())
+	sbtest.Eq(t, 0, f.m.del)
+	// The smallest power of two number of groups that fits 100 elements at
+	// the frozen load factor
+	numGroups := len(f.m.groups)
+	sbtest.True(t, 100*100 <= _frozenLoadFactor*numGroups*slotprobes.GroupSize)
+	sbtest.True(t, numGroups == 1 ||
+		100*100 > _frozenLoadFactor*numGroups/2*slotprobes.GroupSize)
+
+	for i := range int32(100) {
+		val, ok := f.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+	_, ok := f.Get(100)
+	sbtest.False(t, ok)
+
+	// Freezing copies the map
+	h.Put(100, 100)
+	_, ok = f.Get(100)
+	sbtest.False(t, ok)
+
+	cntr := 0
+	for k, v := range f.All() {
+		sbtest.Eq(t, k, v)
+		cntr++
+	}
+	sbtest.Eq(t, 100, cntr)
+}
+
+func TestFreezeEmpty(t *testing.T) {
+	h := New[int32, int32]()
+	f := h.Freeze()
+	sbtest.Eq(t, 0, f.Len())
+	sbtest.Eq(t, 1, len(f.m.groups))
+	_, ok := f.Get(1)
+	sbtest.False(t, ok)
+}
+
+func TestThaw(t *testing.T) {
+	h := New[int32, int32]()
+	for i := range int32(100) {
+		h.Put(i, i)
+	}
+	f := h.Freeze()
+	th := f.Thaw()
+	for i := range int32(1000) {
+		th.Put(i, -i)
+	}
+	sbtest.Eq(t, 1000, th.Len())
+	sbtest.Eq(t, 100, f.Len())
+	val, _ := f.Get(1)
+	sbtest.Eq(t, int32(1), val)
+}
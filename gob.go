@@ -0,0 +1,75 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+type (
+	// The representation of a Map that is given to gob. Keys and values are
+	// kept in separate slices so gob only has to describe each type once.
+	gobMap[K any, V any] struct {
+		Keys []K
+		Vals []V
+	}
+)
+
+// Encodes the map with [encoding/gob]. The hash and equality functions are not
+// encoded, refer to [Map.GobDecode] for how they are supplied when decoding.
+func (m Map[K, V]) GobEncode() ([]byte, error) {
+	data := gobMap[K, V]{
+		Keys: make([]K, 0, m.Len()),
+		Vals: make([]V, 0, m.Len()),
+	}
+	for k, v := range m.All() {
+		data.Keys = append(data.Keys, k)
+		data.Vals = append(data.Vals, v)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decodes a map that was encoded with [Map.GobEncode] into the map. Like a
+// builtin map, values that are already in the map are kept unless they are
+// overwritten by a decoded value. The map is grown once up front to fit all of
+// the decoded values.
+//
+// Hash and equality functions cannot be encoded so the map must be created
+// before it is decoded into. Maps that were created with [New] can simply be
+// created again with [New]. Maps that were created with [NewCustom] must be
+// created again with [NewCustom], supplying the same hash and equality
+// functions. If the map was not created with a constructor
+// [ErrUninitializedMap] is returned.
+func (m *Map[K, V]) GobDecode(b []byte) error {
+	if m.hash == nil || m.eq == nil {
+		return ErrUninitializedMap
+	}
+
+	var data gobMap[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+	if len(data.Keys) != len(data.Vals) {
+		return errMismatchedGobLens
+	}
+	m.Reserve(len(data.Keys))
+	for i := range data.Keys {
+		m.Put(data.Keys[i], data.Vals[i])
+	}
+	return nil
+}
+
+// Encodes the map in the same format as [Map.GobEncode].
+func (m Map[K, V]) MarshalBinary() ([]byte, error) {
+	return m.GobEncode()
+}
+
+// Decodes the map in the same way as [Map.GobDecode], including the same
+// requirements for the hash and equality functions.
+func (m *Map[K, V]) UnmarshalBinary(b []byte) error {
+	return m.GobDecode(b)
+}
@@ -0,0 +1,78 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	h := New[int32, string]()
+	for i := range int32(1000) {
+		h.Put(i, strings.Repeat("a", int(i%10)))
+	}
+
+	data, err := h.GobEncode()
+	sbtest.Eq(t, nil, err)
+	res := New[int32, string]()
+	res.Put(-1, "kept")
+	res.Put(0, "overwritten")
+	sbtest.Eq(t, nil, res.GobDecode(data))
+	sbtest.Eq(t, 1001, res.Len())
+	for k, v := range h.All() {
+		val, ok := res.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, v, val)
+	}
+	val, ok := res.Get(-1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "kept", val)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	h := NewCaseInsensitive[int]()
+	h.Put("Content-Type", 1)
+	h.Put("Accept", 2)
+
+	data, err := h.MarshalBinary()
+	sbtest.Eq(t, nil, err)
+	res := NewCaseInsensitive[int]()
+	sbtest.Eq(t, nil, res.UnmarshalBinary(data))
+	sbtest.Eq(t, 2, res.Len())
+	val, ok := res.Get("content-type")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 1, val)
+
+	var empty Map[string, int]
+	sbtest.True(t, errors.Is(empty.UnmarshalBinary(data), ErrUninitializedMap))
+}
+
+func TestGobInStruct(t *testing.T) {
+	type cache struct {
+		Name    string
+		Entries Map[string, []int]
+	}
+	newEntries := func() Map[string, []int] {
+		return NewCustom[string, []int](
+			_defaultInitialCap, ComparableEqual[string], ComparableHash[string](),
+		)
+	}
+
+	c := cache{Name: "test", Entries: newEntries()}
+	c.Entries.Put("a", []int{1, 2})
+	c.Entries.Put("b", []int{3})
+	var buf bytes.Buffer
+	sbtest.Eq(t, nil, gob.NewEncoder(&buf).Encode(c))
+
+	res := cache{Entries: newEntries()}
+	sbtest.Eq(t, nil, gob.NewDecoder(&buf).Decode(&res))
+	sbtest.Eq(t, "test", res.Name)
+	sbtest.Eq(t, 2, res.Entries.Len())
+	val, ok := res.Entries.Get("a")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, []int{1, 2}, val)
+}
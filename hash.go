@@ -0,0 +1,257 @@
+package sbmap
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"reflect"
+	"unsafe"
+)
+
+// The largest array, in bytes, that will be hashed by the array fast path in
+// [ComparableHashSeed]. Larger arrays are hashed with [maphash.Comparable].
+const _maxFastArraySize = 64
+
+// An equality function that can be passed to [NewCustom] when using a
+// comparable type. If the key type is comparable then you can simply use [New]
+// instead of [NewCustom] and this function will be Used by default.
+func ComparableEqual[T comparable](l T, r T) bool {
+	return l == r
+}
+
+// A hash function that can be passed to [NewCustom] when using a comparable
+// type. If the key type is comparable then you can simply use [New] instead of
+// [NewCustom] and this function will be Used by default. This is the same as
+// calling [ComparableHashSeed] with a seed of zero.
+func ComparableHash[T comparable]() func(v T) uint64 {
+	return ComparableHashSeed[T](0)
+}
+
+// A seeded hash function that can be passed to [NewCustom] when using a
+// comparable type. Maps that use the same seed and have the same operations
+// applied to them in the same order will have the same layout. This is useful
+// for reproducible tests and for giving separate maps separate layouts without
+// changing any global state. Refer to [NewSeeded] for creating a Map that uses
+// this hash function.
+//
+// Types with an underlying type of one of the following kinds are hashed
+// without reflection:
+//   - integers: with a seed of zero the value itself is Used as the hash. This
+//     is fast but keys that share their low bits will share a slot hash, refer
+//     to [ComparableMixedHash] if that is a concern. With any other seed the
+//     value is mixed with the seed so that each seed gives a different layout.
+//   - bools
+//   - floats: the bits of the value are mixed. -0 and +0 are equal so they hash
+//     to the same value. NaN is never equal to anything, including itself, so
+//     every NaN gets a random hash. Just like the builtin map this means every
+//     Put with a NaN key adds a new entry that can never be retrieved or
+//     removed by key, only by iterating or clearing the map.
+//   - strings: with a seed of zero hashed with [maphash.String], with any
+//     other seed the bytes of the string are mixed with the seed.
+//   - pointers and channels: the address is mixed. The address is only stable
+//     within a single process.
+//   - arrays of integers that are at most 64 bytes: the bytes of the array are
+//     mixed.
+//
+// All other types are hashed with [maphash.Comparable], with the result mixed
+// with the seed when it is not zero. Integers, bools, floats, arrays of
+// integers, and strings with a non-zero seed are hashed using only their value
+// and the seed so their layout is reproducible across processes. All other
+// types are also hashed with a per process seed so their layout is only
+// reproducible within a single process.
+func ComparableHashSeed[T comparable](seed uint64) func(v T) uint64 {
+	t := reflect.TypeFor[T]()
+	if seed != 0 && (isIntKind(t.Kind()) || t.Kind() == reflect.Bool) {
+		// XORing the seed onto the identity hash would leave every group and
+		// slot hash collision in place, so the seed is mixed in instead
+		identity := ComparableHashSeed[T](0)
+		return func(v T) uint64 {
+			return mix64(identity(v), seed)
+		}
+	}
+	// The underlying type of T is read through unsafe pointer casts rather
+	// than type assertions so that named types, such as `type ID int`, are
+	// supported.
+	switch t.Kind() {
+	case reflect.Int:
+		return func(v T) uint64 {
+			return uint64(*(*int)(unsafe.Pointer(&v)))
+		}
+	case reflect.Int8:
+		return func(v T) uint64 {
+			return uint64(*(*int8)(unsafe.Pointer(&v)))
+		}
+	case reflect.Int16:
+		return func(v T) uint64 {
+			return uint64(*(*int16)(unsafe.Pointer(&v)))
+		}
+	case reflect.Int32:
+		return func(v T) uint64 {
+			return uint64(*(*int32)(unsafe.Pointer(&v)))
+		}
+	case reflect.Int64:
+		return func(v T) uint64 {
+			return uint64(*(*int64)(unsafe.Pointer(&v)))
+		}
+	case reflect.Uint:
+		return func(v T) uint64 {
+			return uint64(*(*uint)(unsafe.Pointer(&v)))
+		}
+	case reflect.Uint8:
+		return func(v T) uint64 {
+			return uint64(*(*uint8)(unsafe.Pointer(&v)))
+		}
+	case reflect.Uint16:
+		return func(v T) uint64 {
+			return uint64(*(*uint16)(unsafe.Pointer(&v)))
+		}
+	case reflect.Uint32:
+		return func(v T) uint64 {
+			return uint64(*(*uint32)(unsafe.Pointer(&v)))
+		}
+	case reflect.Uint64:
+		return func(v T) uint64 {
+			return *(*uint64)(unsafe.Pointer(&v))
+		}
+	case reflect.Uintptr:
+		return func(v T) uint64 {
+			return uint64(*(*uintptr)(unsafe.Pointer(&v)))
+		}
+	case reflect.Bool:
+		return func(v T) uint64 {
+			return uint64(*(*uint8)(unsafe.Pointer(&v)))
+		}
+	case reflect.Float32:
+		return func(v T) uint64 {
+			f := *(*float32)(unsafe.Pointer(&v))
+			if f != f {
+				return rand.Uint64()
+			}
+			if f == 0 {
+				// Converts -0 to +0
+				f = 0
+			}
+			return mix64(uint64(math.Float32bits(f)), seed)
+		}
+	case reflect.Float64:
+		return func(v T) uint64 {
+			f := *(*float64)(unsafe.Pointer(&v))
+			if f != f {
+				return rand.Uint64()
+			}
+			if f == 0 {
+				// Converts -0 to +0
+				f = 0
+			}
+			return mix64(math.Float64bits(f), seed)
+		}
+	case reflect.String:
+		if seed != 0 {
+			return func(v T) uint64 {
+				s := *(*string)(unsafe.Pointer(&v))
+				return hashBytes(unsafe.Slice(unsafe.StringData(s), len(s)), seed)
+			}
+		}
+		return func(v T) uint64 {
+			return maphash.String(_comparableSeed, *(*string)(unsafe.Pointer(&v)))
+		}
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		return func(v T) uint64 {
+			return mix64(uint64(*(*uintptr)(unsafe.Pointer(&v))), seed)
+		}
+	case reflect.Array:
+		if isIntKind(t.Elem().Kind()) && t.Size() <= _maxFastArraySize {
+			return func(v T) uint64 {
+				return hashBytes(
+					unsafe.Slice((*byte)(unsafe.Pointer(&v)), unsafe.Sizeof(v)),
+					seed,
+				)
+			}
+		}
+	}
+	if seed != 0 {
+		return func(v T) uint64 {
+			return mix64(maphash.Comparable(_comparableSeed, v), seed)
+		}
+	}
+	return func(v T) uint64 {
+		return maphash.Comparable(_comparableSeed, v)
+	}
+}
+
+// A seeded hash function that can be passed to [NewCustom] when using a
+// comparable type. It is the same as [ComparableHashSeed] except that integer
+// keys are always passed through a fast mixing function, even with a seed of
+// zero, rather than being used as the hash directly. The identity hash that
+// [ComparableHash] uses for integers is fast, but keys that share their low
+// bits, such as multiples of 128 or sequential IDs with a stride, all end up
+// with the same slot hash. Mixing spreads every bit of the key across the
+// entire hash so that strided or adversarial keys are distributed evenly
+// across the map.
+func ComparableMixedHash[T comparable](seed uint64) func(v T) uint64 {
+	if isIntKind(reflect.TypeFor[T]().Kind()) {
+		identity := ComparableHash[T]()
+		return func(v T) uint64 {
+			return mix64(identity(v), seed)
+		}
+	}
+	return ComparableHashSeed[T](seed)
+}
+
+// Returns true if [ComparableHashSeed] hashes values of the supplied type using
+// only their value and the seed for every seed, meaning the hashes are the same
+// in every process.
+func comparableHashIsStable(t reflect.Type) bool {
+	switch k := t.Kind(); {
+	case isIntKind(k), k == reflect.Bool,
+		k == reflect.Float32, k == reflect.Float64:
+		return true
+	case k == reflect.Array:
+		return isIntKind(t.Elem().Kind()) && t.Size() <= _maxFastArraySize
+	default:
+		return false
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// A fast avalanching mix function in the style of the wyhash and xxh3
+// finalizers. Every bit of the input affects every bit of the output and the
+// seed selects one of many unrelated mixing functions.
+func mix64(v uint64, seed uint64) uint64 {
+	// The multiplier is forced to be odd so that no seed can zero the product
+	hi, lo := bits.Mul64(v^0xa0761d6478bd642f, (seed^0xe7037ed1a0b428db)|1)
+	hi, lo = bits.Mul64(hi^lo, 0x8ebc6af09c88c6e3)
+	return hi ^ lo
+}
+
+// Hashes a short byte slice sixteen bytes at a time in the style of wyhash.
+func hashBytes(b []byte, seed uint64) uint64 {
+	rv := seed ^ uint64(len(b))
+	var tail [16]byte
+	for len(b) > 0 {
+		chunk := b
+		if len(b) < 16 {
+			copy(tail[:], b)
+			chunk = tail[:]
+		}
+		hi, lo := bits.Mul64(
+			binary.LittleEndian.Uint64(chunk)^0xa0761d6478bd642f,
+			binary.LittleEndian.Uint64(chunk[8:])^rv,
+		)
+		rv = hi ^ lo
+		b = b[min(16, len(b)):]
+	}
+	return mix64(rv, seed)
+}
@@ -0,0 +1,137 @@
+package sbmap
+
+import (
+	"hash/maphash"
+	"math"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type namedInt int
+
+func TestComparableHashNamedTypes(t *testing.T) {
+	sbtest.Eq(t, uint64(5), ComparableHash[namedInt]()(5))
+
+	h := New[namedInt, int]()
+	for i := range 100 {
+		h.Put(namedInt(i), i)
+	}
+	val, ok := h.Get(50)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 50, val)
+}
+
+func TestComparableHashInts(t *testing.T) {
+	sbtest.Eq(t, uint64(math.MaxUint64), ComparableHash[int8]()(-1))
+	sbtest.Eq(t, uint64(0xff), ComparableHash[uint8]()(0xff))
+	sbtest.Eq(t, uint64(1<<40), ComparableHash[int64]()(1<<40))
+	sbtest.Eq(t, mix64(1<<40, 3), ComparableHashSeed[uint64](3)(1<<40))
+}
+
+func TestComparableHashString(t *testing.T) {
+	sbtest.Eq(t, maphash.String(_comparableSeed, "abc"), ComparableHash[string]()("abc"))
+	// Seeded string hashes do not depend on the per process seed
+	sbtest.Eq(t, hashBytes([]byte("abc"), 3), ComparableHashSeed[string](3)("abc"))
+}
+
+func TestComparableHashSeedChangesCollisions(t *testing.T) {
+	collides := func(seed uint64, l int, r int) bool {
+		h := NewSeeded[int, int](_defaultInitialCap, seed)
+		lGroup, lSlot := h.splitHash(h.hash(l))
+		rGroup, rSlot := h.splitHash(h.hash(r))
+		return h.clampedGroupHash(lGroup) == h.clampedGroupHash(rGroup) &&
+			lSlot == rSlot
+	}
+
+	// The keys share their low bits so the identity hash always collides
+	sbtest.True(t, collides(0, 5, 5+128*64))
+	numCollisions := 0
+	for seed := range uint64(32) {
+		if collides(seed+1, 5, 5+128*64) {
+			numCollisions++
+		}
+	}
+	sbtest.True(t, numCollisions < 2)
+}
+
+func TestComparableHashBool(t *testing.T) {
+	sbtest.True(t, ComparableHash[bool]()(true) != ComparableHash[bool]()(false))
+
+	h := New[bool, int]()
+	h.Put(true, 1)
+	h.Put(false, 0)
+	h.Put(true, 2)
+	sbtest.Eq(t, 2, h.Len())
+	val, ok := h.Get(true)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+}
+
+func TestComparableHashFloats(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	sbtest.Eq(t, ComparableHash[float64]()(0), ComparableHash[float64]()(negZero))
+	sbtest.Eq(
+		t,
+		ComparableHash[float32]()(0),
+		ComparableHash[float32]()(float32(negZero)),
+	)
+	sbtest.True(t, ComparableHash[float64]()(1) != ComparableHash[float64]()(2))
+
+	h := New[float64, int]()
+	h.Put(0, 1)
+	h.Put(negZero, 2)
+	sbtest.Eq(t, 1, h.Len())
+	val, ok := h.Get(0)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+
+	// NaN behaves the same as the builtin map, every put adds a new entry
+	// that cannot be retrieved
+	h.Put(math.NaN(), 3)
+	h.Put(math.NaN(), 4)
+	sbtest.Eq(t, 3, h.Len())
+	_, ok = h.Get(math.NaN())
+	sbtest.False(t, ok)
+	h.Remove(math.NaN())
+	sbtest.Eq(t, 3, h.Len())
+}
+
+func TestComparableHashPointers(t *testing.T) {
+	a, b := new(int), new(int)
+	sbtest.Eq(t, ComparableHash[*int]()(a), ComparableHash[*int]()(a))
+	sbtest.True(t, ComparableHash[*int]()(a) != ComparableHash[*int]()(b))
+
+	h := New[*int, int]()
+	h.Put(a, 1)
+	h.Put(b, 2)
+	val, ok := h.Get(a)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 1, val)
+}
+
+func TestComparableHashArrays(t *testing.T) {
+	sbtest.Eq(
+		t,
+		ComparableHash[[3]int16]()([3]int16{1, 2, 3}),
+		ComparableHash[[3]int16]()([3]int16{1, 2, 3}),
+	)
+	sbtest.True(
+		t,
+		ComparableHash[[3]int16]()([3]int16{1, 2, 3}) !=
+			ComparableHash[[3]int16]()([3]int16{1, 2, 4}),
+	)
+	sbtest.True(
+		t,
+		ComparableHash[[9]uint8]()([9]uint8{8: 1}) !=
+			ComparableHash[[9]uint8]()([9]uint8{}),
+	)
+
+	h := New[[4]int, int]()
+	for i := range 100 {
+		h.Put([4]int{i, i + 1, i + 2, i + 3}, i)
+	}
+	val, ok := h.Get([4]int{50, 51, 52, 53})
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 50, val)
+}
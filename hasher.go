@@ -0,0 +1,225 @@
+package sbmap
+
+import (
+	"math/bits"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+type (
+	// A type that provides the hash and equality functions for a key type. A
+	// Hasher can carry state, such as a seed, that would otherwise need to be
+	// captured by the closures given to [NewCustom]. If two values are equal the
+	// Hash method should return the same hash for both values.
+	Hasher[K any] interface {
+		Hash(v K) uint64
+		Equal(l K, r K) bool
+	}
+
+	// A constraint for key types that know how to hash and compare themselves.
+	// If two values are equal the Hash method should return the same hash for
+	// both values.
+	Hashable[K any] interface {
+		Hash() uint64
+		Equal(other K) bool
+	}
+
+	// A map whose keys hash and compare themselves. [HashableMap.Get],
+	// [HashableMap.Put], and [HashableMap.Remove] call the keys Hash and Equal
+	// methods directly while probing rather than going through the functions
+	// stored in the embedded [Map]. All other methods are provided by the
+	// embedded Map, which is given the same methods as its hash and equality
+	// functions, so the two can be freely mixed.
+	HashableMap[K Hashable[K], V any] struct {
+		Map[K, V]
+	}
+
+	// A map that uses a [Hasher] to hash and compare its keys.
+	// [HasherMap.Get], [HasherMap.Put], and [HasherMap.Remove] call the
+	// hashers Hash and Equal methods directly while probing rather than going
+	// through the functions stored in the embedded [Map]. All other methods
+	// are provided by the embedded Map, which is given the same methods as its
+	// hash and equality functions, so the two can be freely mixed.
+	HasherMap[K any, V any, H Hasher[K]] struct {
+		Map[K, V]
+		h H
+	}
+)
+
+// Creates a HasherMap where K is the key type and V is the value type with a
+// capacity of `_cap`. The Hash and Equal methods of `h` will be Used by the
+// map. Refer to BenchmarkHasher for a comparison with [NewCustom].
+func NewHasher[K any, V any, H Hasher[K]](_cap int, h H) HasherMap[K, V, H] {
+	return HasherMap[K, V, H]{
+		Map: NewCustom[K, V](_cap, h.Equal, h.Hash),
+		h:   h,
+	}
+}
+
+// Finds the supplied key, calling the hashers Hash and Equal methods directly.
+// If the key is found the group and slot index of the key are returned along
+// with true. Otherwise the group and slot index of the first empty slot in the
+// keys probe sequence are returned along with false.
+func (m *HasherMap[K, V, H]) probe(k K) (uint64, int, uint8, bool) {
+	groupHash, slotHash := m.splitHash(m.h.Hash(k))
+	groupHash = m.clampedGroupHash(groupHash)
+	// All probing is performed on the group level
+	doubleHash := m.doubleHash(groupHash)
+
+	for i := uint64(1); ; i++ {
+		potentialMatches, emptySlots := slotprobes.SlotProbe(
+			slotHash,
+			m.groups[groupHash].flags,
+			m.groups[groupHash].slotKeys,
+		)
+
+		for j := 0; potentialMatches > 0 || emptySlots > 0; {
+			tz := min(
+				bits.TrailingZeros(uint(potentialMatches)),
+				bits.TrailingZeros(uint(emptySlots)),
+			)
+			potentialMatches >>= tz
+			emptySlots >>= tz
+			j += tz
+
+			if emptySlots&0b1 == 1 {
+				return groupHash, j, slotHash, false
+			}
+			if potentialMatches&0b1 == 1 && m.h.Equal(m.groups[groupHash].slots[j].key, k) {
+				return groupHash, j, slotHash, true
+			}
+			potentialMatches = potentialMatches >> 1
+			emptySlots = emptySlots >> 1
+			j++
+		}
+
+		groupHash = m.clampedGroupHash(groupHash + i*doubleHash)
+	}
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned.
+func (m *HasherMap[K, V, H]) Get(k K) (V, bool) {
+	if groupIdx, slotIdx, _, ok := m.probe(k); ok {
+		return m.groups[groupIdx].slots[slotIdx].value, true
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Places the supplied key, value pair in the map. If the key was already
+// present in the map the old value will be overwritten. The map will rehash as
+// necessary.
+func (m *HasherMap[K, V, H]) Put(k K, v V) {
+	m.growIfFull()
+
+	groupIdx, slotIdx, slotHash, ok := m.probe(k)
+	if ok {
+		m.groups[groupIdx].slots[slotIdx].value = v
+		return
+	}
+	m.groups[groupIdx].slots[slotIdx] = slot[K, V]{key: k, value: v}
+	m.groups[groupIdx].slotKeys[slotIdx] = slotHash
+	m.groups[groupIdx].flags[slotIdx] |= slotprobes.Used
+	m.len++
+}
+
+// Removes the supplied key and associated value from the map if it is present.
+// If the key is not present in the map then no action will be taken.
+func (m *HasherMap[K, V, H]) Remove(k K) {
+	if groupIdx, slotIdx, _, ok := m.probe(k); ok {
+		m.tombstone(groupIdx, slotIdx)
+	}
+	m.shrinkIfSparse()
+}
+
+// Creates a HashableMap where K is the key type and V is the value type. The
+// keys own Hash and Equal methods will be Used by the map, so no hash or
+// equality functions need to be supplied. Refer to BenchmarkHasher for a
+// comparison of the direct and stored function paths.
+func NewHashable[K Hashable[K], V any]() HashableMap[K, V] {
+	return HashableMap[K, V]{
+		Map: NewCustom[K, V](_defaultInitialCap, K.Equal, K.Hash),
+	}
+}
+
+// Finds the supplied key, calling its Hash and Equal methods directly. If the
+// key is found the group and slot index of the key are returned along with
+// true. Otherwise the group and slot index of the first empty slot in the keys
+// probe sequence are returned along with false.
+func (m *HashableMap[K, V]) probe(k K) (uint64, int, uint8, bool) {
+	groupHash, slotHash := m.splitHash(k.Hash())
+	groupHash = m.clampedGroupHash(groupHash)
+	// All probing is performed on the group level
+	doubleHash := m.doubleHash(groupHash)
+
+	for i := uint64(1); ; i++ {
+		potentialMatches, emptySlots := slotprobes.SlotProbe(
+			slotHash,
+			m.groups[groupHash].flags,
+			m.groups[groupHash].slotKeys,
+		)
+
+		for j := 0; potentialMatches > 0 || emptySlots > 0; {
+			tz := min(
+				bits.TrailingZeros(uint(potentialMatches)),
+				bits.TrailingZeros(uint(emptySlots)),
+			)
+			potentialMatches >>= tz
+			emptySlots >>= tz
+			j += tz
+
+			if emptySlots&0b1 == 1 {
+				return groupHash, j, slotHash, false
+			}
+			if potentialMatches&0b1 == 1 && k.Equal(m.groups[groupHash].slots[j].key) {
+				return groupHash, j, slotHash, true
+			}
+			potentialMatches = potentialMatches >> 1
+			emptySlots = emptySlots >> 1
+			j++
+		}
+
+		groupHash = m.clampedGroupHash(groupHash + i*doubleHash)
+	}
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned.
+func (m *HashableMap[K, V]) Get(k K) (V, bool) {
+	if groupIdx, slotIdx, _, ok := m.probe(k); ok {
+		return m.groups[groupIdx].slots[slotIdx].value, true
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Places the supplied key, value pair in the map. If the key was already
+// present in the map the old value will be overwritten. The map will rehash as
+// necessary.
+func (m *HashableMap[K, V]) Put(k K, v V) {
+	m.growIfFull()
+
+	groupIdx, slotIdx, slotHash, ok := m.probe(k)
+	if ok {
+		m.groups[groupIdx].slots[slotIdx].value = v
+		return
+	}
+	m.groups[groupIdx].slots[slotIdx] = slot[K, V]{key: k, value: v}
+	m.groups[groupIdx].slotKeys[slotIdx] = slotHash
+	m.groups[groupIdx].flags[slotIdx] |= slotprobes.Used
+	m.len++
+}
+
+// Removes the supplied key and associated value from the map if it is present.
+// If the key is not present in the map then no action will be taken.
+func (m *HashableMap[K, V]) Remove(k K) {
+	if groupIdx, slotIdx, _, ok := m.probe(k); ok {
+		m.tombstone(groupIdx, slotIdx)
+	}
+	m.shrinkIfSparse()
+}
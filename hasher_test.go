@@ -0,0 +1,151 @@
+package sbmap
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	// Hashes by the absolute value of the key so that 1 and -1 are equal.
+	absHasher struct{}
+
+	seededHasher struct {
+		seed uint64
+	}
+)
+
+func (_ absHasher) Hash(v int) uint64 {
+	return uint64(max(v, -v))
+}
+
+func (_ absHasher) Equal(l int, r int) bool {
+	return max(l, -l) == max(r, -r)
+}
+
+func (h seededHasher) Hash(v int) uint64 {
+	return uint64(v) ^ h.seed
+}
+
+func (_ seededHasher) Equal(l int, r int) bool {
+	return l == r
+}
+
+func TestNewHasher(t *testing.T) {
+	h := NewHasher[int, string](_defaultInitialCap, absHasher{})
+	h.Put(1, "one")
+	h.Put(-1, "negative one")
+	h.Put(2, "two")
+	sbtest.Eq(t, 2, h.Len())
+
+	val, ok := h.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "negative one", val)
+
+	h.Remove(-2)
+	sbtest.Eq(t, 1, h.Len())
+}
+
+func TestNewHasherWithState(t *testing.T) {
+	h := NewHasher[int, int](_defaultInitialCap, seededHasher{seed: 0xff})
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+	sbtest.Eq(t, 1000, h.Len())
+	for i := range 1000 {
+		val, ok := h.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+func TestHasherMapMixesWithMap(t *testing.T) {
+	h := NewHasher[int, int](_defaultInitialCap, absHasher{})
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+	// The embedded map uses the same methods so keys placed by either can be
+	// found by the other
+	h.Map.Put(-5, 50)
+	val, ok := h.Get(5)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 50, val)
+	val, ok = h.Map.Get(-6)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 6, val)
+	sbtest.Eq(t, 1000, h.Len())
+
+	for i := range 990 {
+		h.Remove(-i)
+	}
+	sbtest.Eq(t, 10, h.Len())
+	_, ok = h.Get(5)
+	sbtest.False(t, ok)
+	for i := 990; i < 1000; i++ {
+		val, ok := h.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+type caseInsensitiveKey string
+
+func (k caseInsensitiveKey) Hash() uint64 {
+	return ComparableHash[string]()(strings.ToLower(string(k)))
+}
+
+func (k caseInsensitiveKey) Equal(other caseInsensitiveKey) bool {
+	return strings.EqualFold(string(k), string(other))
+}
+
+func TestNewHashable(t *testing.T) {
+	h := NewHashable[caseInsensitiveKey, int]()
+	h.Put("one", 1)
+	h.Put("ONE", 2)
+	h.Put("two", 2)
+	sbtest.Eq(t, 2, h.Len())
+
+	val, ok := h.Get("One")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+
+	for i := range 1000 {
+		h.Put(caseInsensitiveKey(strconv.Itoa(i)), i)
+	}
+	for i := range 1000 {
+		val, ok := h.Get(caseInsensitiveKey(strconv.Itoa(i)))
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+func TestHashableMapMixesWithMap(t *testing.T) {
+	h := NewHashable[caseInsensitiveKey, int]()
+	for i := range 1000 {
+		h.Put(caseInsensitiveKey("key"+strconv.Itoa(i)), i)
+	}
+	// The embedded map uses the same methods so keys placed by either can be
+	// found by the other
+	h.Map.Put("KEY5", 50)
+	val, ok := h.Get("key5")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 50, val)
+	val, ok = h.Map.Get("Key6")
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 6, val)
+	sbtest.Eq(t, 1000, h.Len())
+
+	for i := range 990 {
+		h.Remove(caseInsensitiveKey("KEY" + strconv.Itoa(i)))
+	}
+	sbtest.Eq(t, 10, h.Len())
+	_, ok = h.Get("key5")
+	sbtest.False(t, ok)
+	for i := 990; i < 1000; i++ {
+		val, ok := h.Get(caseInsensitiveKey("key" + strconv.Itoa(i)))
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
@@ -0,0 +1,465 @@
+package sbmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"iter"
+	"math"
+	"reflect"
+	"slices"
+	"unsafe"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// An image is a copy of the maps groups exactly as they are laid out in
+// memory, so it can be loaded without rehashing any keys. It starts with a
+// header of little endian values, padded to _imageHeaderSize bytes:
+//
+//	magic        [8]byte  "SBMAPIMG"
+//	version      uint32   currently 1
+//	byte order   uint32   0x01020304 in the writers native byte order
+//	group size   uint32   the slotprobes.GroupSize of the writing build
+//	group bytes  uint32   the size in bytes of a single group
+//	layout       uint64   a fingerprint of the key and value type layouts
+//	seed         uint64   the seed the maps hash function was given
+//	groups       uint64   the number of groups
+//	len          uint64   the maps internal length, including deleted slots
+//	del          uint64   the number of deleted slots
+//	hash kind    uint32   1 if the map used the comparable hash, 0 otherwise
+//	data crc     uint32   CRC32 of the groups
+//	header crc   uint32   CRC32 of the preceding header fields
+//
+// The groups follow the header in the native byte order of the writer. All
+// checksums use the Castagnoli polynomial.
+
+type (
+	imageHeader struct {
+		seed           uint64
+		numGroups      uint64
+		len            uint64
+		del            uint64
+		comparableHash bool
+		dataCRC        uint32
+	}
+
+	// A read only map that is backed by a memory mapped image. Loading a
+	// MappedImage only validates the images header, the groups are paged in by
+	// the operating system as they are Used. The image must not be modified
+	// while it is mapped and the MappedImage must not be Used after it is
+	// closed.
+	MappedImage[K any, V any] struct {
+		m    Map[K, V]
+		data []byte
+	}
+)
+
+const (
+	ImageVersion uint32 = 1
+
+	_imageMagic      = "SBMAPIMG"
+	_imageByteOrder  = 0x01020304
+	_imageHeaderSize = 128
+	// The most groups that will be allocated before reading them. Larger
+	// images are read in chunks so a corrupt group count cannot cause a huge
+	// allocation.
+	_imageMaxPrealloc = 1 << 16
+
+	_imageCustomHash     uint32 = 0
+	_imageComparableHash uint32 = 1
+)
+
+var (
+	// The image does not start with the image magic bytes.
+	ErrNotImage = errors.New("sbmap: not a map image")
+	// The image was written with a version of the format that this build
+	// cannot read.
+	ErrImageVersion = errors.New("sbmap: unsupported map image version")
+	// The image was written by a build with a different slotprobes.GroupSize.
+	ErrImageGroupSize = errors.New("sbmap: map image has a different group size")
+	// The image was written with key or value types that have a different
+	// memory layout, or on a machine with a different byte order.
+	ErrImageLayout = errors.New("sbmap: map image has an incompatible type layout")
+	// The header or groups checksum did not match its data.
+	ErrImageChecksum = errors.New("sbmap: map image checksum mismatch")
+	// The image ended before all of its groups were read.
+	ErrImageTruncated = errors.New("sbmap: map image is truncated")
+	// The header has a valid checksum but describes groups that no map could
+	// have, such as a group count that is not a power of two.
+	ErrImageCorrupt = errors.New("sbmap: map image is corrupt")
+	// The key or value type contains pointers, which cannot be written to an
+	// image.
+	ErrImagePointers = errors.New("sbmap: map image types must not contain pointers")
+	// The key types comparable hash is not the same across processes, so the
+	// image would need to be rehashed. Use the Custom load functions with a
+	// hash function that only depends on the keys value.
+	ErrImageUnstableHash = errors.New(
+		"sbmap: map image key type does not have a stable comparable hash",
+	)
+	// The image was written by a map that did not use the comparable hash, so
+	// the hash function cannot be recreated. Use ReadImageCustom or
+	// MapImageCustom with the hash function of the map that wrote the image.
+	ErrImageCustomHash = errors.New(
+		"sbmap: map image was written with a custom hash, use ReadImageCustom or MapImageCustom",
+	)
+)
+
+// Writes the maps groups to `w` as an image that can be loaded without
+// rehashing. Only maps whose key and value types contain no pointers can be
+// written, otherwise [ErrImagePointers] is returned. If the map was not created
+// with a constructor [ErrUninitializedMap] is returned. The seed of maps
+// created with [NewSeeded] is saved so that [ReadImage] and [MapImage] can
+// recreate the same hash function. Whether the map used the comparable hash is
+// also saved so that images of maps created with [NewCustom] are refused by
+// [ReadImage] and [MapImage].
+func (m *Map[K, V]) WriteImage(w io.Writer) error {
+	if m.hash == nil || m.eq == nil {
+		return ErrUninitializedMap
+	}
+	if hasPointers(reflect.TypeFor[K]()) || hasPointers(reflect.TypeFor[V]()) {
+		return ErrImagePointers
+	}
+
+	data := groupBytes(m.groups)
+	header := make([]byte, 0, _imageHeaderSize)
+	header = append(header, _imageMagic...)
+	header = binary.LittleEndian.AppendUint32(header, ImageVersion)
+	header = binary.NativeEndian.AppendUint32(header, _imageByteOrder)
+	header = binary.LittleEndian.AppendUint32(header, slotprobes.GroupSize)
+	header = binary.LittleEndian.AppendUint32(header, uint32(unsafe.Sizeof(group[K, V]{})))
+	header = binary.LittleEndian.AppendUint64(header, imageLayout[K, V]())
+	header = binary.LittleEndian.AppendUint64(header, m.seed)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(m.groups)))
+	header = binary.LittleEndian.AppendUint64(header, uint64(m.len))
+	header = binary.LittleEndian.AppendUint64(header, uint64(m.del))
+	hashKind := _imageCustomHash
+	if m.comparableHash {
+		hashKind = _imageComparableHash
+	}
+	header = binary.LittleEndian.AppendUint32(header, hashKind)
+	header = binary.LittleEndian.AppendUint32(header, crc32.Checksum(data, _crcTable))
+	header = binary.LittleEndian.AppendUint32(header, crc32.Checksum(header, _crcTable))
+	header = header[:_imageHeaderSize]
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// Reads an image written by [Map.WriteImage], copying the groups into a new
+// Map. The map will use [ComparableEqual] and [ComparableHashSeed] with the
+// seed saved in the image. Only key types whose comparable hash is the same in
+// every process can be loaded this way, refer to [ComparableHashSeed]. For
+// other key types [ErrImageUnstableHash] is returned and [ReadImageCustom]
+// must be Used instead. Images written by a map that did not use the comparable
+// hash, such as one created with [NewCustom], are refused with
+// [ErrImageCustomHash] and must also be read with [ReadImageCustom].
+func ReadImage[K comparable, V any](r io.Reader) (Map[K, V], error) {
+	if !comparableHashIsStable(reflect.TypeFor[K]()) {
+		return Map[K, V]{}, ErrImageUnstableHash
+	}
+	return readImage[K, V](r, ComparableEqual[K], ComparableHashSeed[K], true)
+}
+
+// Reads an image written by [Map.WriteImage], copying the groups into a new
+// Map that uses the supplied `eq` and `hash` functions. The hash function must
+// return the same hashes as the hash function of the map that wrote the image,
+// including in a different process.
+func ReadImageCustom[K any, V any](
+	r io.Reader,
+	eq func(l K, r K) bool,
+	hash func(v K) uint64,
+) (Map[K, V], error) {
+	return readImage[K, V](
+		r, eq, func(_ uint64) func(v K) uint64 { return hash }, false,
+	)
+}
+
+func readImage[K any, V any](
+	r io.Reader,
+	eq func(l K, r K) bool,
+	newHash func(seed uint64) func(v K) uint64,
+	comparableHash bool,
+) (Map[K, V], error) {
+	rawHeader := make([]byte, _imageHeaderSize)
+	if _, err := io.ReadFull(r, rawHeader); err != nil {
+		return Map[K, V]{}, imageReadErr(err)
+	}
+	header, err := parseImageHeader[K, V](rawHeader)
+	if err != nil {
+		return Map[K, V]{}, err
+	}
+	if comparableHash && !header.comparableHash {
+		return Map[K, V]{}, ErrImageCustomHash
+	}
+
+	// A crafted image can have a valid header checksum and a huge group
+	// count, so only a bounded number of groups are allocated up front. After
+	// that the groups grow by at most the amount that has been read.
+	groups := make([]group[K, V], 0, min(header.numGroups, _imageMaxPrealloc))
+	crc := uint32(0)
+	for uint64(len(groups)) < header.numGroups {
+		start := len(groups)
+		chunk := min(
+			int(header.numGroups)-start, max(cap(groups)-start, start),
+		)
+		groups = slices.Grow(groups, chunk)[:start+chunk]
+		data := groupBytes(groups[start:])
+		if _, err := io.ReadFull(r, data); err != nil {
+			return Map[K, V]{}, imageReadErr(err)
+		}
+		crc = crc32.Update(crc, _crcTable, data)
+	}
+	if crc != header.dataCRC {
+		return Map[K, V]{}, ErrImageChecksum
+	}
+
+	return Map[K, V]{
+		// The groups are probed using their capacity, which must be exactly
+		// the number of groups
+		groups:         slices.Clip(groups),
+		len:            int(header.len),
+		del:            int(header.del),
+		eq:             eq,
+		hash:           newHash(header.seed),
+		seed:           header.seed,
+		comparableHash: comparableHash,
+	}, nil
+}
+
+// Memory maps the image at `path` as a read only map, refer to [ReadImage] for
+// the requirements on the key type. The groups checksum is not verified so
+// that the image does not need to be read in full. Memory mapping is only
+// supported on linux, on other platforms an error wrapping
+// [errors.ErrUnsupported] is returned.
+func MapImage[K comparable, V any](path string) (*MappedImage[K, V], error) {
+	if !comparableHashIsStable(reflect.TypeFor[K]()) {
+		return nil, ErrImageUnstableHash
+	}
+	return mapImage[K, V](path, ComparableEqual[K], ComparableHashSeed[K], true)
+}
+
+// Memory maps the image at `path` as a read only map that uses the supplied
+// `eq` and `hash` functions, refer to [ReadImageCustom] for the requirements on
+// the hash function and [MapImage] for details about the mapping.
+func MapImageCustom[K any, V any](
+	path string,
+	eq func(l K, r K) bool,
+	hash func(v K) uint64,
+) (*MappedImage[K, V], error) {
+	return mapImage[K, V](
+		path, eq, func(_ uint64) func(v K) uint64 { return hash }, false,
+	)
+}
+
+func mapImage[K any, V any](
+	path string,
+	eq func(l K, r K) bool,
+	newHash func(seed uint64) func(v K) uint64,
+	comparableHash bool,
+) (*MappedImage[K, V], error) {
+	data, err := mmapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := newMappedImage[K, V](data, eq, newHash, comparableHash)
+	if err != nil {
+		munmap(data)
+		return nil, err
+	}
+	return rv, nil
+}
+
+func newMappedImage[K any, V any](
+	data []byte,
+	eq func(l K, r K) bool,
+	newHash func(seed uint64) func(v K) uint64,
+	comparableHash bool,
+) (*MappedImage[K, V], error) {
+	if len(data) < _imageHeaderSize {
+		return nil, ErrImageTruncated
+	}
+	header, err := parseImageHeader[K, V](data[:_imageHeaderSize])
+	if err != nil {
+		return nil, err
+	}
+	if comparableHash && !header.comparableHash {
+		return nil, ErrImageCustomHash
+	}
+	// The header checks bound the group count so this cannot overflow
+	groupsSize := header.numGroups * uint64(unsafe.Sizeof(group[K, V]{}))
+	if uint64(len(data)-_imageHeaderSize) < groupsSize {
+		return nil, ErrImageTruncated
+	}
+
+	return &MappedImage[K, V]{
+		m: Map[K, V]{
+			// The mapping is page aligned and the header size is a multiple
+			// of every types alignment so the groups are correctly aligned.
+			groups: unsafe.Slice(
+				(*group[K, V])(unsafe.Pointer(&data[_imageHeaderSize])),
+				header.numGroups,
+			),
+			len:            int(header.len),
+			del:            int(header.del),
+			eq:             eq,
+			hash:           newHash(header.seed),
+			seed:           header.seed,
+			comparableHash: comparableHash,
+		},
+		data: data,
+	}, nil
+}
+
+// Unmaps the image. The MappedImage must not be Used after it is closed.
+func (m *MappedImage[K, V]) Close() error {
+	data := m.data
+	m.data = nil
+	m.m = Map[K, V]{}
+	return munmap(data)
+}
+
+// Returns the number of elements in the map.
+func (m *MappedImage[K, V]) Len() int {
+	return m.m.Len()
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned.
+func (m *MappedImage[K, V]) Get(k K) (V, bool) {
+	return m.m.Get(k)
+}
+
+// Iterates over all of the keys in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (m *MappedImage[K, V]) Keys() iter.Seq[K] {
+	return m.m.Keys()
+}
+
+// Iterates over all of the key, value pairs in the map. Uses the stdlib `iter`
+// package so this function can be Used in a standard `for` loop.
+func (m *MappedImage[K, V]) All() iter.Seq2[K, V] {
+	return m.m.All()
+}
+
+// Iterates over all of the values in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (m *MappedImage[K, V]) Vals() iter.Seq[V] {
+	return m.m.Vals()
+}
+
+func parseImageHeader[K any, V any](b []byte) (imageHeader, error) {
+	if string(b[0:8]) != _imageMagic {
+		return imageHeader{}, ErrNotImage
+	}
+	if binary.LittleEndian.Uint32(b[8:12]) != ImageVersion {
+		return imageHeader{}, ErrImageVersion
+	}
+	if crc32.Checksum(b[:72], _crcTable) != binary.LittleEndian.Uint32(b[72:76]) {
+		return imageHeader{}, ErrImageChecksum
+	}
+	if binary.LittleEndian.Uint32(b[16:20]) != slotprobes.GroupSize {
+		return imageHeader{}, ErrImageGroupSize
+	}
+	if binary.NativeEndian.Uint32(b[12:16]) != _imageByteOrder ||
+		binary.LittleEndian.Uint32(b[20:24]) != uint32(unsafe.Sizeof(group[K, V]{})) ||
+		binary.LittleEndian.Uint64(b[24:32]) != imageLayout[K, V]() {
+		return imageHeader{}, ErrImageLayout
+	}
+
+	rv := imageHeader{
+		seed:      binary.LittleEndian.Uint64(b[32:40]),
+		numGroups: binary.LittleEndian.Uint64(b[40:48]),
+		len:       binary.LittleEndian.Uint64(b[48:56]),
+		del:       binary.LittleEndian.Uint64(b[56:64]),
+		dataCRC:   binary.LittleEndian.Uint32(b[68:72]),
+	}
+	switch binary.LittleEndian.Uint32(b[64:68]) {
+	case _imageCustomHash:
+	case _imageComparableHash:
+		rv.comparableHash = true
+	default:
+		return imageHeader{}, ErrImageCorrupt
+	}
+	// Maps always have a power of two number of groups and the group count
+	// is bounded so that the size of the groups fits in an int
+	if rv.numGroups == 0 || rv.numGroups&(rv.numGroups-1) != 0 ||
+		rv.numGroups > math.MaxInt/uint64(unsafe.Sizeof(group[K, V]{})) ||
+		rv.len > rv.numGroups*slotprobes.GroupSize || rv.del > rv.len {
+		return imageHeader{}, ErrImageCorrupt
+	}
+	return rv, nil
+}
+
+func imageReadErr(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrImageTruncated
+	}
+	return err
+}
+
+// Returns the memory that backs the supplied groups.
+func groupBytes[K any, V any](groups []group[K, V]) []byte {
+	if len(groups) == 0 {
+		return []byte{}
+	}
+	return unsafe.Slice(
+		(*byte)(unsafe.Pointer(unsafe.SliceData(groups))),
+		uintptr(len(groups))*unsafe.Sizeof(groups[0]),
+	)
+}
+
+// Returns a fingerprint of the memory layout of the key and value types. Two
+// types with the same fingerprint have the same kind, size, alignment, and field
+// offsets all the way down, regardless of their names.
+func imageLayout[K any, V any]() uint64 {
+	h := fnv.New64a()
+	h.Write(appendTypeLayout(nil, reflect.TypeFor[K]()))
+	h.Write(appendTypeLayout(nil, reflect.TypeFor[V]()))
+	return h.Sum64()
+}
+
+func appendTypeLayout(b []byte, t reflect.Type) []byte {
+	b = append(b, byte(t.Kind()))
+	b = binary.LittleEndian.AppendUint64(b, uint64(t.Size()))
+	b = binary.LittleEndian.AppendUint64(b, uint64(t.Align()))
+	switch t.Kind() {
+	case reflect.Array:
+		b = binary.LittleEndian.AppendUint64(b, uint64(t.Len()))
+		b = appendTypeLayout(b, t.Elem())
+	case reflect.Struct:
+		b = binary.LittleEndian.AppendUint64(b, uint64(t.NumField()))
+		for i := range t.NumField() {
+			b = binary.LittleEndian.AppendUint64(b, uint64(t.Field(i).Offset))
+			b = appendTypeLayout(b, t.Field(i).Type)
+		}
+	}
+	return b
+}
+
+// Returns true if values of the supplied type contain any pointers.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Slice,
+		reflect.String, reflect.Interface, reflect.Chan, reflect.Func:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package sbmap
+
+import (
+	"os"
+	"syscall"
+)
+
+// Maps the entire file at `path` into memory as read only.
+func mmapFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// The mapping stays valid after the file is closed
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, ErrImageTruncated
+	}
+	return syscall.Mmap(
+		int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED,
+	)
+}
+
+func munmap(data []byte) error {
+	if data == nil {
+		return nil
+	}
+	return syscall.Munmap(data)
+}
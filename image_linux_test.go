@@ -0,0 +1,83 @@
+//go:build linux
+
+package sbmap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestMapImage(t *testing.T) {
+	h := newTestImageMap()
+	path := filepath.Join(t.TempDir(), "map.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, writeTestImage(t, &h), 0o644))
+
+	res, err := MapImage[int64, imageValue](path)
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, h.Len(), res.Len())
+	for k, v := range h.All() {
+		val, ok := res.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, v, val)
+	}
+	_, ok := res.Get(0)
+	sbtest.False(t, ok)
+	cntr := 0
+	for k, v := range res.All() {
+		val, _ := h.Get(k)
+		sbtest.Eq(t, val, v)
+		cntr++
+	}
+	sbtest.Eq(t, h.Len(), cntr)
+	sbtest.Eq(t, nil, res.Close())
+}
+
+func TestMapImageRefusesIncompatibleImages(t *testing.T) {
+	h := newTestImageMap()
+	data := writeTestImage(t, &h)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "truncated.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, data[:len(data)-1], 0o644))
+	_, err := MapImage[int64, imageValue](path)
+	sbtest.True(t, errors.Is(err, ErrImageTruncated))
+
+	path = filepath.Join(dir, "groupSize.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, setImageHeaderField(data, 16, 3), 0o644))
+	_, err = MapImage[int64, imageValue](path)
+	sbtest.True(t, errors.Is(err, ErrImageGroupSize))
+
+	path = filepath.Join(dir, "empty.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, nil, 0o644))
+	_, err = MapImage[int64, imageValue](path)
+	sbtest.True(t, errors.Is(err, ErrImageTruncated))
+
+	_, err = MapImage[int64, imageValue](filepath.Join(dir, "missing.img"))
+	sbtest.True(t, errors.Is(err, os.ErrNotExist))
+
+	path = filepath.Join(dir, "groups.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, setImageHeaderField64(data, 40, 1<<62), 0o644))
+	_, err = MapImage[int64, imageValue](path)
+	sbtest.True(t, errors.Is(err, ErrImageCorrupt))
+
+	custom := NewCustom[int64, imageValue](
+		_defaultInitialCap, ComparableEqual[int64], ComparableMixedHash[int64](9),
+	)
+	custom.Put(1, imageValue{A: 1})
+	path = filepath.Join(dir, "custom.img")
+	sbtest.Eq(t, nil, os.WriteFile(path, writeTestImage(t, &custom), 0o644))
+	_, err = MapImage[int64, imageValue](path)
+	sbtest.True(t, errors.Is(err, ErrImageCustomHash))
+	res, err := MapImageCustom[int64, imageValue](
+		path, ComparableEqual[int64], ComparableMixedHash[int64](9),
+	)
+	sbtest.Eq(t, nil, err)
+	val, ok := res.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, int32(1), val.A)
+	sbtest.Eq(t, nil, res.Close())
+}
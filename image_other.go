@@ -0,0 +1,19 @@
+//go:build !linux
+
+package sbmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+func mmapFile(path string) ([]byte, error) {
+	return nil, fmt.Errorf(
+		"sbmap: memory mapped images are only supported on linux: %w",
+		errors.ErrUnsupported,
+	)
+}
+
+func munmap(data []byte) error {
+	return nil
+}
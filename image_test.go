@@ -0,0 +1,253 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	imageValue struct {
+		A int32
+		B [3]uint8
+		C float64
+	}
+
+	imageKey struct {
+		A int32
+		B int64
+	}
+)
+
+func writeTestImage(t *testing.T, h *Map[int64, imageValue]) []byte {
+	var buf bytes.Buffer
+	sbtest.Eq(t, nil, h.WriteImage(&buf))
+	return buf.Bytes()
+}
+
+func newTestImageMap() Map[int64, imageValue] {
+	h := NewSeeded[int64, imageValue](_defaultInitialCap, 42)
+	for i := range int64(1000) {
+		h.Put(i, imageValue{A: int32(i), B: [3]uint8{1, 2, 3}, C: float64(i) / 2})
+	}
+	for i := int64(0); i < 1000; i += 3 {
+		h.Remove(i)
+	}
+	return h
+}
+
+// Changes a header field and updates the header checksum so that only the
+// changed field is wrong.
+func setImageHeaderField(data []byte, offset int, v uint32) []byte {
+	rv := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(rv[offset:], v)
+	binary.LittleEndian.PutUint32(rv[72:], crc32.Checksum(rv[:72], _crcTable))
+	return rv
+}
+
+func setImageHeaderField64(data []byte, offset int, v uint64) []byte {
+	rv := bytes.Clone(data)
+	binary.LittleEndian.PutUint64(rv[offset:], v)
+	binary.LittleEndian.PutUint32(rv[72:], crc32.Checksum(rv[:72], _crcTable))
+	return rv
+}
+
+func TestImageRoundTrip(t *testing.T) {
+	h := newTestImageMap()
+	data := writeTestImage(t, &h)
+
+	res, err := ReadImage[int64, imageValue](bytes.NewReader(data))
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, h.Len(), res.Len())
+	sbtest.Eq(t, uint64(42), res.seed)
+	sbtest.Eq(t, h.groups, res.groups)
+	for k, v := range h.All() {
+		val, ok := res.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, v, val)
+	}
+	_, ok := res.Get(0)
+	sbtest.False(t, ok)
+
+	// The copied map is an ordinary map that can still be modified
+	for i := range int64(2000) {
+		res.Put(i, imageValue{A: int32(i)})
+	}
+	sbtest.Eq(t, 2000, res.Len())
+
+	// And it still uses the comparable hash when written again
+	res2, err := ReadImage[int64, imageValue](bytes.NewReader(writeTestImage(t, &res)))
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, 2000, res2.Len())
+}
+
+func TestImageLargerThanPrealloc(t *testing.T) {
+	// The groups are read in several chunks
+	h := NewCap[int8, int8](4 * _imageMaxPrealloc)
+	for i := range int8(100) {
+		h.Put(i, -i)
+	}
+	var buf bytes.Buffer
+	sbtest.Eq(t, nil, h.WriteImage(&buf))
+	res, err := ReadImage[int8, int8](bytes.NewReader(buf.Bytes()))
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, 4*_imageMaxPrealloc, cap(res.groups))
+	sbtest.Eq(t, h.groups, res.groups)
+	for i := range int8(100) {
+		val, ok := res.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, -i, val)
+	}
+}
+
+func TestImageCustomHash(t *testing.T) {
+	h := NewCustom[int64, imageValue](
+		_defaultInitialCap, ComparableEqual[int64], ComparableMixedHash[int64](9),
+	)
+	for i := range int64(100) {
+		h.Put(i, imageValue{A: int32(i)})
+	}
+	data := writeTestImage(t, &h)
+
+	_, err := ReadImage[int64, imageValue](bytes.NewReader(data))
+	sbtest.True(t, errors.Is(err, ErrImageCustomHash))
+
+	res, err := ReadImageCustom[int64, imageValue](
+		bytes.NewReader(data), ComparableEqual[int64], ComparableMixedHash[int64](9),
+	)
+	sbtest.Eq(t, nil, err)
+	for i := range int64(100) {
+		val, ok := res.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, int32(i), val.A)
+	}
+	// Writing the custom map again keeps it marked as custom
+	_, err = ReadImage[int64, imageValue](bytes.NewReader(writeTestImage(t, &res)))
+	sbtest.True(t, errors.Is(err, ErrImageCustomHash))
+}
+
+func TestImageRefusesUninitializedMap(t *testing.T) {
+	var h Map[int64, imageValue]
+	sbtest.True(t, errors.Is(h.WriteImage(&bytes.Buffer{}), ErrUninitializedMap))
+}
+
+func TestImageRefusesCorruptHeaders(t *testing.T) {
+	h := newTestImageMap()
+	data := writeTestImage(t, &h)
+	numGroups := uint64(len(h.groups))
+
+	for _, corrupt := range [][]byte{
+		setImageHeaderField64(data, 40, 0),
+		setImageHeaderField64(data, 40, numGroups+1),
+		setImageHeaderField64(data, 40, 1<<62),
+		setImageHeaderField64(data, 48, numGroups*slotprobes.GroupSize+1),
+		setImageHeaderField64(data, 56, uint64(h.len)+1),
+		setImageHeaderField(data, 64, 7),
+	} {
+		_, err := ReadImage[int64, imageValue](bytes.NewReader(corrupt))
+		sbtest.True(t, errors.Is(err, ErrImageCorrupt))
+	}
+
+	// A valid but large group count is only allocated as the groups are read
+	_, err := ReadImage[int64, imageValue](
+		bytes.NewReader(setImageHeaderField64(data, 40, 1<<40)),
+	)
+	sbtest.True(t, errors.Is(err, ErrImageTruncated))
+}
+
+func TestImageCustom(t *testing.T) {
+	hash := func(v imageKey) uint64 { return mix64(uint64(v.A)^uint64(v.B), 0) }
+	h := NewCustom[imageKey, int32](_defaultInitialCap, ComparableEqual[imageKey], hash)
+	for i := range int32(100) {
+		h.Put(imageKey{A: i, B: int64(i) * 2}, i)
+	}
+	var buf bytes.Buffer
+	sbtest.Eq(t, nil, h.WriteImage(&buf))
+
+	_, err := ReadImage[imageKey, int32](bytes.NewReader(buf.Bytes()))
+	sbtest.True(t, errors.Is(err, ErrImageUnstableHash))
+
+	res, err := ReadImageCustom[imageKey, int32](
+		bytes.NewReader(buf.Bytes()), ComparableEqual[imageKey], hash,
+	)
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, 100, res.Len())
+	for i := range int32(100) {
+		val, ok := res.Get(imageKey{A: i, B: int64(i) * 2})
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+func TestImageRefusesPointers(t *testing.T) {
+	h := New[string, int]()
+	sbtest.True(t, errors.Is(h.WriteImage(&bytes.Buffer{}), ErrImagePointers))
+	h2 := New[int, *int]()
+	sbtest.True(t, errors.Is(h2.WriteImage(&bytes.Buffer{}), ErrImagePointers))
+	h3 := NewCustom[int, [2]struct{ A []int }](
+		_defaultInitialCap, ComparableEqual[int], ComparableHash[int](),
+	)
+	sbtest.True(t, errors.Is(h3.WriteImage(&bytes.Buffer{}), ErrImagePointers))
+}
+
+func TestImageRefusesIncompatibleImages(t *testing.T) {
+	h := newTestImageMap()
+	data := writeTestImage(t, &h)
+
+	_, err := ReadImage[int64, int64](bytes.NewReader(data))
+	sbtest.True(t, errors.Is(err, ErrImageLayout))
+	_, err = ReadImage[uint64, imageValue](bytes.NewReader(data))
+	sbtest.True(t, errors.Is(err, ErrImageLayout))
+	// Type names are not part of the layout
+	type renamedKey int64
+	type renamedValue struct {
+		X int32
+		Y [3]uint8
+		Z float64
+	}
+	_, err = ReadImage[renamedKey, renamedValue](bytes.NewReader(data))
+	sbtest.Eq(t, nil, err)
+	type reordered struct {
+		C float64
+		B [3]uint8
+		A int32
+	}
+	_, err = ReadImage[int64, reordered](bytes.NewReader(data))
+	sbtest.True(t, errors.Is(err, ErrImageLayout))
+
+	_, err = ReadImage[int64, imageValue](
+		bytes.NewReader(setImageHeaderField(data, 16, 3)),
+	)
+	sbtest.True(t, errors.Is(err, ErrImageGroupSize))
+	_, err = ReadImage[int64, imageValue](
+		bytes.NewReader(setImageHeaderField(data, 12, 0x04030201)),
+	)
+	sbtest.True(t, errors.Is(err, ErrImageLayout))
+	_, err = ReadImage[int64, imageValue](
+		bytes.NewReader(setImageHeaderField(data, 8, 2)),
+	)
+	sbtest.True(t, errors.Is(err, ErrImageVersion))
+
+	corrupt := bytes.Clone(data)
+	corrupt[0] = 'X'
+	_, err = ReadImage[int64, imageValue](bytes.NewReader(corrupt))
+	sbtest.True(t, errors.Is(err, ErrNotImage))
+	corrupt = bytes.Clone(data)
+	corrupt[40]++
+	_, err = ReadImage[int64, imageValue](bytes.NewReader(corrupt))
+	sbtest.True(t, errors.Is(err, ErrImageChecksum))
+	corrupt = bytes.Clone(data)
+	corrupt[len(data)/2]++
+	_, err = ReadImage[int64, imageValue](bytes.NewReader(corrupt))
+	sbtest.True(t, errors.Is(err, ErrImageChecksum))
+
+	for _, l := range []int{0, 10, _imageHeaderSize, len(data) - 1} {
+		_, err = ReadImage[int64, imageValue](bytes.NewReader(data[:l]))
+		sbtest.True(t, errors.Is(err, ErrImageTruncated))
+	}
+}
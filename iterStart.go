@@ -0,0 +1,9 @@
+//go:build !sbmap_randiter
+
+package sbmap
+
+// Returns the group and slot that iteration should start at. Iteration always
+// starts at the first slot unless the sbmap_randiter build tag is supplied.
+func iterStart(numGroups int) (int, int) {
+	return 0, 0
+}
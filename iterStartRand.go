@@ -0,0 +1,19 @@
+//go:build sbmap_randiter
+
+package sbmap
+
+import (
+	"math/rand/v2"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// Returns a random group and slot for iteration to start at. Similar to the
+// builtin map this is meant to catch code that depends on the iteration order
+// of the map, which is otherwise deterministic.
+func iterStart(numGroups int) (int, int) {
+	if numGroups == 0 {
+		return 0, 0
+	}
+	return rand.IntN(numGroups), rand.IntN(slotprobes.GroupSize)
+}
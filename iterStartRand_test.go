@@ -0,0 +1,27 @@
+//go:build sbmap_randiter
+
+package sbmap
+
+import (
+	"slices"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestRandomizedIterationOrder(t *testing.T) {
+	h := New[int, int]()
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+
+	first := slices.Collect(h.Keys())
+	sbtest.Eq(t, 1000, len(first))
+	differs := false
+	for range 10 {
+		keys := slices.Collect(h.Keys())
+		sbtest.SlicesMatchUnordered(t, first, keys)
+		differs = differs || !slices.Equal(first, keys)
+	}
+	sbtest.True(t, differs)
+}
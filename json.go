@@ -0,0 +1,266 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Describes how keys are written as JSON. Keys that can be written as
+	// JSON strings are written as an object, all other keys are written as an
+	// array of key, value pairs.
+	jsonKeyFormat int
+
+	jsonObjectEntry struct {
+		key string
+		val []byte
+	}
+)
+
+const (
+	jsonKeyPairs jsonKeyFormat = iota
+	jsonKeyString
+	jsonKeyText
+	jsonKeyInt
+	jsonKeyUint
+)
+
+var (
+	_textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	_textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Returns how keys of the supplied type are encoded, using the same rules as
+// [json.Marshal] uses for builtin map keys.
+func jsonEncodeKeyFormat(t reflect.Type) jsonKeyFormat {
+	if t.Kind() == reflect.String {
+		return jsonKeyString
+	}
+	if t.Implements(_textMarshalerType) {
+		return jsonKeyText
+	}
+	return jsonIntKeyFormat(t)
+}
+
+// Returns how keys of the supplied type are decoded, using the same rules as
+// [json.Unmarshal] uses for builtin map keys.
+func jsonDecodeKeyFormat(t reflect.Type) jsonKeyFormat {
+	if reflect.PointerTo(t).Implements(_textUnmarshalerType) {
+		return jsonKeyText
+	}
+	if t.Kind() == reflect.String {
+		return jsonKeyString
+	}
+	return jsonIntKeyFormat(t)
+}
+
+func jsonIntKeyFormat(t reflect.Type) jsonKeyFormat {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return jsonKeyInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return jsonKeyUint
+	default:
+		return jsonKeyPairs
+	}
+}
+
+// Encodes the map as JSON. Maps with string keys, integer keys, or keys that
+// implement [encoding.TextMarshaler] are encoded as a JSON object in the same
+// format that [json.Marshal] uses for a builtin map, including sorting the
+// keys. All other maps are encoded as an array of [key, value] pairs. A map
+// that was not created with a constructor is encoded as null.
+func (m Map[K, V]) MarshalJSON() ([]byte, error) {
+	if m.groups == nil {
+		return []byte("null"), nil
+	}
+
+	format := jsonEncodeKeyFormat(reflect.TypeFor[K]())
+	if format == jsonKeyPairs {
+		return m.marshalJSONPairs()
+	}
+
+	entries := make([]jsonObjectEntry, 0, m.Len())
+	for k, v := range m.All() {
+		key, err := marshalJSONKey(k, format)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, jsonObjectEntry{key: key, val: val})
+	}
+	slices.SortFunc(entries, func(l, r jsonObjectEntry) int {
+		return strings.Compare(l.key, r.key)
+	})
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(e.val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func marshalJSONKey[K any](k K, format jsonKeyFormat) (string, error) {
+	kv := reflect.ValueOf(&k).Elem()
+	switch format {
+	case jsonKeyString:
+		return kv.String(), nil
+	case jsonKeyText:
+		if kv.Kind() == reflect.Pointer && kv.IsNil() {
+			return "", nil
+		}
+		buf, err := any(k).(encoding.TextMarshaler).MarshalText()
+		return string(buf), err
+	case jsonKeyInt:
+		return strconv.FormatInt(kv.Int(), 10), nil
+	default:
+		return strconv.FormatUint(kv.Uint(), 10), nil
+	}
+}
+
+func (m Map[K, V]) marshalJSONPairs() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	first := true
+	for k, v := range m.All() {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte('[')
+		buf.Write(key)
+		buf.WriteByte(',')
+		buf.Write(val)
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// Decodes either of the formats produced by [Map.MarshalJSON] into the map.
+// Like a builtin map, values that are already in the map are kept unless they
+// are overwritten by a decoded value, and null leaves the map unchanged. The
+// map is grown once up front to fit all of the decoded values. The map must
+// have been created with a constructor so that it has hash and equality
+// functions, otherwise [ErrUninitializedMap] is returned.
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if m.hash == nil || m.eq == nil {
+		return ErrUninitializedMap
+	}
+
+	if len(data) > 0 && data[0] == '[' {
+		return m.unmarshalJSONPairs(data)
+	}
+	format := jsonDecodeKeyFormat(reflect.TypeFor[K]())
+	if len(data) == 0 || data[0] != '{' || format == jsonKeyPairs {
+		return &json.UnmarshalTypeError{
+			Value: "object", Type: reflect.TypeFor[Map[K, V]](),
+		}
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Reserve(len(raw))
+	for key, val := range raw {
+		k, err := unmarshalJSONKey[K](key, format)
+		if err != nil {
+			return err
+		}
+		var v V
+		if err := json.Unmarshal(val, &v); err != nil {
+			return err
+		}
+		m.Put(k, v)
+	}
+	return nil
+}
+
+func unmarshalJSONKey[K any](key string, format jsonKeyFormat) (K, error) {
+	var rv K
+	kv := reflect.ValueOf(&rv).Elem()
+	switch format {
+	case jsonKeyText:
+		err := any(&rv).(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		return rv, err
+	case jsonKeyString:
+		kv.SetString(key)
+	case jsonKeyInt:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || kv.OverflowInt(n) {
+			return rv, &json.UnmarshalTypeError{
+				Value: "number " + key, Type: kv.Type(),
+			}
+		}
+		kv.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || kv.OverflowUint(n) {
+			return rv, &json.UnmarshalTypeError{
+				Value: "number " + key, Type: kv.Type(),
+			}
+		}
+		kv.SetUint(n)
+	}
+	return rv, nil
+}
+
+func (m *Map[K, V]) unmarshalJSONPairs(data []byte) error {
+	var raw [][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Reserve(len(raw))
+	for _, pair := range raw {
+		if len(pair) != 2 {
+			return &json.UnmarshalTypeError{
+				Value: "array with " + strconv.Itoa(len(pair)) + " elements",
+				Type:  reflect.TypeFor[Map[K, V]](),
+			}
+		}
+		var k K
+		if err := json.Unmarshal(pair[0], &k); err != nil {
+			return err
+		}
+		var v V
+		if err := json.Unmarshal(pair[1], &v); err != nil {
+			return err
+		}
+		m.Put(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package sbmap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	jsonIntKey int8
+
+	jsonStructKey struct {
+		A int
+		B string
+	}
+)
+
+func TestMarshalJSONMatchesBuiltinMap(t *testing.T) {
+	strs := NewCustom[string, int](
+		_defaultInitialCap, ComparableEqual[string], ComparableHash[string](),
+	)
+	builtinStrs := map[string]int{}
+	ints := New[jsonIntKey, string]()
+	builtinInts := map[jsonIntKey]string{}
+	addrs := New[netip.Addr, bool]()
+	builtinAddrs := map[netip.Addr]bool{}
+	for i := range 100 {
+		strs.Put(string(rune('a'+i%26))+"<&>", i)
+		builtinStrs[string(rune('a'+i%26))+"<&>"] = i
+		ints.Put(jsonIntKey(i-50), "val")
+		builtinInts[jsonIntKey(i-50)] = "val"
+		addrs.Put(netip.AddrFrom4([4]byte{10, 0, 0, byte(i)}), i%2 == 0)
+		builtinAddrs[netip.AddrFrom4([4]byte{10, 0, 0, byte(i)})] = i%2 == 0
+	}
+
+	checkMatches := func(v any, builtin any) {
+		res, err := json.Marshal(v)
+		sbtest.Eq(t, nil, err)
+		exp, err := json.Marshal(builtin)
+		sbtest.Eq(t, nil, err)
+		sbtest.Eq(t, string(exp), string(res))
+	}
+	checkMatches(strs, builtinStrs)
+	checkMatches(ints, builtinInts)
+	checkMatches(addrs, builtinAddrs)
+	checkMatches(struct{ M Map[string, int] }{M: strs}, struct{ M map[string]int }{M: builtinStrs})
+	checkMatches(Map[string, int]{}, map[string]int(nil))
+}
+
+func TestUnmarshalJSONFromBuiltinMap(t *testing.T) {
+	builtin := map[jsonIntKey]string{}
+	for i := range 100 {
+		builtin[jsonIntKey(i-50)] = "val"
+	}
+	data, err := json.Marshal(builtin)
+	sbtest.Eq(t, nil, err)
+
+	h := New[jsonIntKey, string]()
+	h.Put(100, "kept")
+	sbtest.Eq(t, nil, json.Unmarshal(data, &h))
+	sbtest.Eq(t, 101, h.Len())
+	for k, v := range builtin {
+		res, ok := h.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, v, res)
+	}
+	res, ok := h.Get(100)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "kept", res)
+
+	sbtest.Eq(t, nil, json.Unmarshal([]byte("null"), &h))
+	sbtest.Eq(t, 101, h.Len())
+
+	var typeErr *json.UnmarshalTypeError
+	sbtest.True(t, errors.As(json.Unmarshal([]byte(`{"128":"a"}`), &h), &typeErr))
+	sbtest.True(t, errors.As(json.Unmarshal([]byte(`{"a":"a"}`), &h), &typeErr))
+}
+
+func TestUnmarshalJSONTextKeys(t *testing.T) {
+	h := New[netip.Addr, int]()
+	sbtest.Eq(t, nil, json.Unmarshal([]byte(`{"10.0.0.1":1,"::1":2}`), &h))
+	sbtest.Eq(t, 2, h.Len())
+	res, ok := h.Get(netip.MustParseAddr("::1"))
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, res)
+	sbtest.True(t, json.Unmarshal([]byte(`{"not an ip":1}`), &h) != nil)
+}
+
+func TestJSONPairsRoundTrip(t *testing.T) {
+	h := NewCustom[jsonStructKey, []int](
+		_defaultInitialCap,
+		ComparableEqual[jsonStructKey],
+		ComparableHash[jsonStructKey](),
+	)
+	for i := range 100 {
+		h.Put(jsonStructKey{A: i, B: "b"}, []int{i, i})
+	}
+	data, err := json.Marshal(h)
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, byte('['), data[0])
+
+	res := NewCustom[jsonStructKey, []int](
+		_defaultInitialCap,
+		ComparableEqual[jsonStructKey],
+		ComparableHash[jsonStructKey](),
+	)
+	sbtest.Eq(t, nil, json.Unmarshal(data, &res))
+	sbtest.Eq(t, 100, res.Len())
+	for i := range 100 {
+		val, ok := res.Get(jsonStructKey{A: i, B: "b"})
+		sbtest.True(t, ok)
+		sbtest.Eq(t, []int{i, i}, val)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	sbtest.True(t, errors.As(json.Unmarshal([]byte(`{"a":[1]}`), &res), &typeErr))
+	sbtest.True(t, errors.As(json.Unmarshal([]byte(`[[{"A":1}]]`), &res), &typeErr))
+}
+
+func TestUnmarshalJSONUninitializedMap(t *testing.T) {
+	var h Map[string, int]
+	sbtest.True(t, errors.Is(json.Unmarshal([]byte(`{"a":1}`), &h), ErrUninitializedMap))
+}
@@ -0,0 +1,175 @@
+// A small library of hash and equality function combinators for building the
+// functions that [sbmap.NewCustom] requires for composite key types such as
+// slices, pairs, and structs.
+package keyhash
+
+import (
+	"bytes"
+	"hash/maphash"
+	"math/bits"
+)
+
+type (
+	// A generic two element key.
+	Pair[A any, B any] struct {
+		First  A
+		Second B
+	}
+
+	// Builds hash and equality functions for a struct one field at a time.
+	// Fields are added with [Field] and [ComparableField].
+	Struct[T any] struct {
+		seed   uint64
+		hashes []func(v T) uint64
+		eqs    []func(l T, r T) bool
+	}
+)
+
+// Combines the running hash `seed` with the hash `h`, returning the new running
+// hash. The result depends on the order the hashes are combined in, so
+// combining a then b gives a different result than combining b then a.
+func HashCombine(seed uint64, h uint64) uint64 {
+	hi, lo := bits.Mul64(seed^0xa0761d6478bd642f, h^0xe7037ed1a0b428db)
+	return hi ^ lo
+}
+
+// Returns a seeded hash function for strings.
+func HashString(seed maphash.Seed) func(v string) uint64 {
+	return func(v string) uint64 {
+		return maphash.String(seed, v)
+	}
+}
+
+// Returns a seeded hash function for byte slices. The hash of a byte slice is
+// the same as the hash of the equivalent string given the same seed.
+func HashBytes(seed maphash.Seed) func(v []byte) uint64 {
+	return func(v []byte) uint64 {
+		return maphash.Bytes(seed, v)
+	}
+}
+
+// An equality function for byte slices that matches [HashBytes].
+func EqualBytes(l []byte, r []byte) bool {
+	return bytes.Equal(l, r)
+}
+
+// Returns a seeded hash function for comparable types.
+func HashComparable[T comparable](seed maphash.Seed) func(v T) uint64 {
+	return func(v T) uint64 {
+		return maphash.Comparable(seed, v)
+	}
+}
+
+// An equality function for comparable types that matches [HashComparable].
+func EqualComparable[T comparable](l T, r T) bool {
+	return l == r
+}
+
+// Returns a hash function for slices that combines the hash of each element,
+// in order, using `hash`.
+func HashSlice[T any](hash func(v T) uint64) func(v []T) uint64 {
+	return func(v []T) uint64 {
+		rv := uint64(len(v))
+		for _, iterV := range v {
+			rv = HashCombine(rv, hash(iterV))
+		}
+		return rv
+	}
+}
+
+// Returns an equality function for slices that matches [HashSlice]. Two slices
+// are equal if they have the same length and all elements are equal according
+// to `eq`.
+func EqualSlice[T any](eq func(l T, r T) bool) func(l []T, r []T) bool {
+	return func(l []T, r []T) bool {
+		if len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !eq(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Returns a hash function for pairs that combines the hash of the first and
+// second elements.
+func HashPair[A any, B any](
+	hashA func(v A) uint64,
+	hashB func(v B) uint64,
+) func(v Pair[A, B]) uint64 {
+	return func(v Pair[A, B]) uint64 {
+		return HashCombine(hashA(v.First), hashB(v.Second))
+	}
+}
+
+// Returns an equality function for pairs that matches [HashPair].
+func EqualPair[A any, B any](
+	eqA func(l A, r A) bool,
+	eqB func(l B, r B) bool,
+) func(l Pair[A, B], r Pair[A, B]) bool {
+	return func(l Pair[A, B], r Pair[A, B]) bool {
+		return eqA(l.First, r.First) && eqB(l.Second, r.Second)
+	}
+}
+
+// Creates a struct builder with no fields. `seed` is the starting value of the
+// running hash.
+func NewStruct[T any](seed uint64) *Struct[T] {
+	return &Struct[T]{seed: seed}
+}
+
+// Adds a field to the struct builder. `get` returns the fields value from the
+// struct and `hash` and `eq` are the fields hash and equality functions. The
+// struct builder is returned so that calls can be chained.
+func Field[T any, F any](
+	s *Struct[T],
+	get func(v T) F,
+	hash func(v F) uint64,
+	eq func(l F, r F) bool,
+) *Struct[T] {
+	s.hashes = append(s.hashes, func(v T) uint64 { return hash(get(v)) })
+	s.eqs = append(s.eqs, func(l T, r T) bool { return eq(get(l), get(r)) })
+	return s
+}
+
+// Adds a comparable field to the struct builder, using [HashComparable] and
+// [EqualComparable] as the fields hash and equality functions.
+func ComparableField[T any, F comparable](
+	s *Struct[T],
+	get func(v T) F,
+	seed maphash.Seed,
+) *Struct[T] {
+	return Field(s, get, HashComparable[F](seed), EqualComparable[F])
+}
+
+// Returns a hash function that combines the hashes of all of the fields that
+// have been added to the struct builder, in the order they were added.
+func (s *Struct[T]) Hash() func(v T) uint64 {
+	hashes := append([]func(v T) uint64{}, s.hashes...)
+	seed := s.seed
+	return func(v T) uint64 {
+		rv := seed
+		for _, h := range hashes {
+			rv = HashCombine(rv, h(v))
+		}
+		return rv
+	}
+}
+
+// Returns an equality function that matches [Struct.Hash]. Two structs are
+// equal if all of the fields that have been added to the struct builder are
+// equal.
+func (s *Struct[T]) Equal() func(l T, r T) bool {
+	eqs := append([]func(l T, r T) bool{}, s.eqs...)
+	return func(l T, r T) bool {
+		for _, eq := range eqs {
+			if !eq(l, r) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,94 @@
+package keyhash
+
+import (
+	"hash/maphash"
+	"testing"
+
+	sbmap "github.com/barbell-math/smoothbrain-hashmap"
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type testKey struct {
+	name  string
+	id    int
+	tags  []string
+	cache *int
+}
+
+func TestHashCombine(t *testing.T) {
+	sbtest.Eq(t, HashCombine(1, 2), HashCombine(1, 2))
+	sbtest.True(t, HashCombine(1, 2) != HashCombine(2, 1))
+	sbtest.True(t, HashCombine(0, 0) != HashCombine(0, 1))
+}
+
+func TestHashBytesMatchesHashString(t *testing.T) {
+	seed := maphash.MakeSeed()
+	sbtest.Eq(t, HashString(seed)("hello"), HashBytes(seed)([]byte("hello")))
+	sbtest.True(t, EqualBytes([]byte("hello"), []byte("hello")))
+	sbtest.False(t, EqualBytes([]byte("hello"), []byte("world")))
+}
+
+func TestSlice(t *testing.T) {
+	seed := maphash.MakeSeed()
+	h := sbmap.NewCustom[[]string, int](
+		4,
+		EqualSlice(EqualComparable[string]),
+		HashSlice(HashString(seed)),
+	)
+	h.Put([]string{"a", "b"}, 1)
+	h.Put([]string{"b", "a"}, 2)
+	h.Put([]string{"a", "b"}, 3)
+	h.Put([]string{}, 4)
+	sbtest.Eq(t, 3, h.Len())
+
+	val, ok := h.Get([]string{"a", "b"})
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 3, val)
+	_, ok = h.Get([]string{"a"})
+	sbtest.False(t, ok)
+}
+
+func TestPair(t *testing.T) {
+	seed := maphash.MakeSeed()
+	h := sbmap.NewCustom[Pair[string, int], int](
+		4,
+		EqualPair(EqualComparable[string], EqualComparable[int]),
+		HashPair(HashString(seed), HashComparable[int](seed)),
+	)
+	for i := range 100 {
+		h.Put(Pair[string, int]{"a", i}, i)
+		h.Put(Pair[string, int]{"b", i}, -i)
+	}
+	sbtest.Eq(t, 200, h.Len())
+
+	val, ok := h.Get(Pair[string, int]{"b", 10})
+	sbtest.True(t, ok)
+	sbtest.Eq(t, -10, val)
+}
+
+func TestStruct(t *testing.T) {
+	seed := maphash.MakeSeed()
+	s := NewStruct[testKey](0)
+	ComparableField(s, func(v testKey) string { return v.name }, seed)
+	ComparableField(s, func(v testKey) int { return v.id }, seed)
+	Field(
+		s,
+		func(v testKey) []string { return v.tags },
+		HashSlice(HashString(seed)),
+		EqualSlice(EqualComparable[string]),
+	)
+
+	h := sbmap.NewCustom[testKey, int](4, s.Equal(), s.Hash())
+	// The cache field is not part of the key
+	h.Put(testKey{name: "a", id: 1, tags: []string{"x"}}, 1)
+	h.Put(testKey{name: "a", id: 1, tags: []string{"x"}, cache: new(int)}, 2)
+	h.Put(testKey{name: "a", id: 1, tags: []string{"y"}}, 3)
+	h.Put(testKey{name: "a", id: 2, tags: []string{"x"}}, 4)
+	sbtest.Eq(t, 3, h.Len())
+
+	val, ok := h.Get(testKey{name: "a", id: 1, tags: []string{"x"}})
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 2, val)
+	_, ok = h.Get(testKey{name: "b", id: 1, tags: []string{"x"}})
+	sbtest.False(t, ok)
+}
@@ -5,7 +5,6 @@ import (
 	"hash/maphash"
 	"iter"
 	"math/bits"
-	"reflect"
 
 	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
 )
@@ -28,6 +27,12 @@ type (
 		del    int
 		eq     func(l K, r K) bool
 		hash   func(l K) uint64
+		// The seed that was given to the hash function, only known for maps
+		// created with [NewSeeded]. It is zero for all other maps.
+		seed uint64
+		// True if hash is the [ComparableHashSeed] hash for seed, meaning the
+		// hash function can be recreated from the seed alone.
+		comparableHash bool
 	}
 )
 
@@ -46,78 +51,17 @@ var (
 	_sliceGrowthFactor = 1
 )
 
-// An equality function that can be passed to [NewCustom] when using a
-// comparable type. If the key type is comparable then you can simply use [New]
-// instead of [NewCustom] and this function will be Used by default.
-func ComparableEqual[T comparable](l T, r T) bool {
-	return l == r
-}
-
-// A hash function that can be passed to [NewCustom] when using a comparable
-// type. If the key type is comparable then you can simply use [New] instead of
-// [NewCustom] and this function will be Used by default.
-func ComparableHash[T comparable]() func(v T) uint64 {
-	// For speed if the underlying type is an int then just return the int
-	// value as the hash. This might be less evenly distributed but is much
-	// faster than the [maphash.Comparable] function.
-	switch reflect.TypeFor[T]().Kind() {
-	case reflect.Int:
-		return func(v T) uint64 {
-			return uint64(any(v).(int))
-		}
-	case reflect.Int8:
-		return func(v T) uint64 {
-			return uint64(any(v).(int8))
-		}
-	case reflect.Int16:
-		return func(v T) uint64 {
-			return uint64(any(v).(int16))
-		}
-	case reflect.Int32:
-		return func(v T) uint64 {
-			return uint64(any(v).(int32))
-		}
-	case reflect.Int64:
-		return func(v T) uint64 {
-			return uint64(any(v).(int64))
-		}
-	case reflect.Uint:
-		return func(v T) uint64 {
-			return uint64(any(v).(uint))
-		}
-	case reflect.Uint8:
-		return func(v T) uint64 {
-			return uint64(any(v).(uint8))
-		}
-	case reflect.Uint16:
-		return func(v T) uint64 {
-			return uint64(any(v).(uint16))
-		}
-	case reflect.Uint32:
-		return func(v T) uint64 {
-			return uint64(any(v).(uint32))
-		}
-	case reflect.Uint64:
-		return func(v T) uint64 {
-			return uint64(any(v).(uint64))
-		}
-	default:
-		return func(v T) uint64 {
-			return maphash.Comparable(_comparableSeed, v)
-		}
-	}
-}
-
 // Creates a Map where K is the key type and V is the value type.
 // [ComparableEqual] and [ComparableHash] functions will be Used by the returned
 // Map. For creating a Map with non-comparable types or custom hash and equality
 // functions refer to [NewCustom].
 func New[K comparable, V comparable]() Map[K, V] {
 	return Map[K, V]{
-		groups: make([]group[K, V], _defaultInitialCap, _defaultInitialCap),
-		len:    0,
-		eq:     ComparableEqual[K],
-		hash:   ComparableHash[K](),
+		groups:         make([]group[K, V], _defaultInitialCap, _defaultInitialCap),
+		len:            0,
+		eq:             ComparableEqual[K],
+		hash:           ComparableHash[K](),
+		comparableHash: true,
 	}
 }
 
@@ -127,10 +71,27 @@ func New[K comparable, V comparable]() Map[K, V] {
 // and equality functions refer to [NewCustom].
 func NewCap[K comparable, V comparable](_cap int) Map[K, V] {
 	return Map[K, V]{
-		groups: make([]group[K, V], _cap, _cap),
-		len:    0,
-		eq:     ComparableEqual[K],
-		hash:   ComparableHash[K](),
+		groups:         make([]group[K, V], _cap, _cap),
+		len:            0,
+		eq:             ComparableEqual[K],
+		hash:           ComparableHash[K](),
+		comparableHash: true,
+	}
+}
+
+// Creates a Map where K is the key type and V is the value type with a capacity
+// of `_cap`. [ComparableEqual] and [ComparableHashSeed] functions will be Used
+// by the returned Map, with `seed` being given to [ComparableHashSeed]. Maps
+// created with the same seed will have the same layout when given the same
+// operations in the same order, refer to [ComparableHashSeed] for details.
+func NewSeeded[K comparable, V any](_cap int, seed uint64) Map[K, V] {
+	return Map[K, V]{
+		groups:         make([]group[K, V], _cap, _cap),
+		len:            0,
+		eq:             ComparableEqual[K],
+		hash:           ComparableHashSeed[K](seed),
+		seed:           seed,
+		comparableHash: true,
 	}
 }
 
@@ -181,7 +142,52 @@ func (m *Map[K, V]) clampedGroupHash(hash uint64) uint64 {
 // is not found the boolean return value will be false and a zero-initialized
 // value of type V will be returned.
 func (m *Map[K, V]) Get(k K) (V, bool) {
-	groupHash, slotHash := m.splitHash(m.hash(k))
+	if groupIdx, slotIdx, ok := m.findSlot(k); ok {
+		return m.groups[groupIdx].slots[slotIdx].value, true
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Gets the value that is related to the key that `q` refers to, without
+// needing to build a value of type K. This allows a map to be searched using a
+// cheaper view of its keys, such as a prefix or a struct of slices. `hash` must
+// return the same hash for `q` that the maps hash function returns for the key
+// `q` refers to, and `eq` must return true only for that key. If the key is
+// found the boolean return value will be true and the value will be returned.
+// If the key is not found the boolean return value will be false and a
+// zero-initialized value of type V will be returned.
+//
+// This is a function rather than a method because methods cannot have their
+// own type parameters.
+func GetWith[K any, V any, Q any](
+	m *Map[K, V],
+	q Q,
+	hash func(q Q) uint64,
+	eq func(q Q, k K) bool,
+) (V, bool) {
+	groupIdx, slotIdx, ok := m.findSlotHashed(
+		hash(q), func(k K) bool { return eq(q, k) },
+	)
+	if ok {
+		return m.groups[groupIdx].slots[slotIdx].value, true
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Finds the group and slot index of the supplied key. The boolean return value
+// will be false if the key is not in the map.
+func (m *Map[K, V]) findSlot(k K) (uint64, int, bool) {
+	return m.findSlotHashed(m.hash(k), func(other K) bool { return m.eq(other, k) })
+}
+
+// Finds the group and slot index of the key that has the supplied hash and
+// that `match` returns true for. The hash must be the same hash the maps hash
+// function would produce for the key. The boolean return value will be false
+// if no such key is in the map.
+func (m *Map[K, V]) findSlotHashed(hash uint64, match func(k K) bool) (uint64, int, bool) {
+	groupHash, slotHash := m.splitHash(hash)
 	groupHash = m.clampedGroupHash(groupHash)
 	// All probing is performed on the group level
 	doubleHash := m.doubleHash(groupHash)
@@ -200,8 +206,8 @@ func (m *Map[K, V]) Get(k K) (V, bool) {
 			emptySlots >>= tz
 			j += tz
 
-			if m.eq(m.groups[groupHash].slots[j].key, k) {
-				return m.groups[groupHash].slots[j].value, true
+			if match(m.groups[groupHash].slots[j].key) {
+				return groupHash, j, true
 			}
 			potentialMatches = potentialMatches >> 1
 			emptySlots = emptySlots >> 1
@@ -210,8 +216,7 @@ func (m *Map[K, V]) Get(k K) (V, bool) {
 		// There should never be a potential match after an empty slot
 		// Meaning, if there is any remaining empty slots, the value was not found
 		if emptySlots > 0 {
-			var tmp V
-			return tmp, false
+			return 0, 0, false
 		}
 
 		groupHash = m.clampedGroupHash(groupHash + i*doubleHash)
@@ -222,13 +227,7 @@ func (m *Map[K, V]) Get(k K) (V, bool) {
 // present in the map the old value will be overwritten. The map will rehash as
 // necessary.
 func (m *Map[K, V]) Put(k K, v V) {
-	// Original equation:
-	// 	len/cap *100 >= _growFactor
-	// Except dividing ints is bad, we want more precision. So remove the
-	// division and we get this:
-	if m.len*100 >= _growFactor*len(m.groups)*slotprobes.GroupSize {
-		m.rehash(cap(m.groups) << _sliceGrowthFactor)
-	}
+	m.growIfFull()
 
 	groupHash, slotHash := m.splitHash(m.hash(k))
 	groupHash = m.clampedGroupHash(groupHash)
@@ -275,12 +274,47 @@ func (m *Map[K, V]) Put(k K, v V) {
 	}
 }
 
+// Doubles the capacity of the map if it is too full to place another element.
+func (m *Map[K, V]) growIfFull() {
+	// Original equation:
+	// 	len/cap *100 >= _growFactor
+	// Except dividing ints is bad, we want more precision. So remove the
+	// division and we get this:
+	if m.len*100 >= _growFactor*len(m.groups)*slotprobes.GroupSize {
+		m.rehash(cap(m.groups) << _sliceGrowthFactor)
+	}
+}
+
+// Grows the map so that `n` more elements can be placed in it without the map
+// needing to rehash. The map is rehashed at most once. If the map already has
+// room for `n` more elements no action will be taken.
+func (m *Map[K, V]) Reserve(n int) {
+	m.reserve(n)
+}
+
+// Reserves room for `n` more elements, returning true if the map was rehashed.
+func (m *Map[K, V]) reserve(n int) bool {
+	if n <= 0 || (m.len+n-1)*100 < _growFactor*len(m.groups)*slotprobes.GroupSize {
+		return false
+	}
+	// Rehashing removes all deleted values so only the live values need to
+	// be accounted for
+	newCap := max(cap(m.groups), 1)
+	for (m.Len()+n-1)*100 >= _growFactor*newCap*slotprobes.GroupSize {
+		newCap <<= _sliceGrowthFactor
+	}
+	m.rehash(newCap)
+	return true
+}
+
 func (m *Map[K, V]) rehash(newCap int) {
 	newHMap := Map[K, V]{
-		groups: make([]group[K, V], newCap, newCap),
-		len:    0,
-		eq:     m.eq,
-		hash:   m.hash,
+		groups:         make([]group[K, V], newCap, newCap),
+		len:            0,
+		eq:             m.eq,
+		hash:           m.hash,
+		seed:           m.seed,
+		comparableHash: m.comparableHash,
 	}
 
 	for i := range m.groups {
@@ -324,8 +358,7 @@ func (m *Map[K, V]) Remove(k K) {
 				goto end
 			}
 			if potentialMatches&0b1 == 1 && m.eq(m.groups[groupHash].slots[j].key, k) {
-				m.del += int(((^m.groups[groupHash].flags[j]) & slotprobes.Deleted) >> 1)
-				m.groups[groupHash].flags[j] |= slotprobes.Deleted
+				m.tombstone(groupHash, j)
 				goto end
 			}
 			potentialMatches = potentialMatches >> 1
@@ -337,6 +370,20 @@ func (m *Map[K, V]) Remove(k K) {
 	}
 
 end:
+	m.shrinkIfSparse()
+}
+
+// Marks the slot at the supplied location as deleted. The slots key and value
+// are zeroed so that they can be garbage collected. The slot is not reused
+// until the map is rehashed.
+func (m *Map[K, V]) tombstone(groupIdx uint64, slotIdx int) {
+	m.del += int(((^m.groups[groupIdx].flags[slotIdx]) & slotprobes.Deleted) >> 1)
+	m.groups[groupIdx].flags[slotIdx] |= slotprobes.Deleted
+	m.groups[groupIdx].slots[slotIdx] = slot[K, V]{}
+}
+
+// Halves the capacity of the map if enough elements have been removed.
+func (m *Map[K, V]) shrinkIfSparse() {
 	// Original equation:
 	// 	len/cap *100 <= _shrinkFactor
 	// Except dividing ints is bad, we want more precision. So remove the
@@ -379,10 +426,12 @@ func (m *Map[K, V]) Copy() *Map[K, V] {
 	}
 
 	return &Map[K, V]{
-		groups: newData,
-		len:    m.len,
-		eq:     m.eq,
-		hash:   m.hash,
+		groups:         newData,
+		len:            m.len,
+		eq:             m.eq,
+		hash:           m.hash,
+		seed:           m.seed,
+		comparableHash: m.comparableHash,
 	}
 }
 
@@ -390,12 +439,21 @@ func (m *Map[K, V]) Copy() *Map[K, V] {
 // this function can be Used in a standard `for` loop.
 func (m *Map[K, V]) Keys() iter.Seq[K] {
 	return func(yield func(k K) bool) {
-		for i := range m.groups {
-			for j := range m.groups[i].slots {
-				if m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
-					!yield(m.groups[i].slots[j].key) {
-					return
-				}
+		for s := range m.liveSlots() {
+			if !yield(s.key) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over all of the key, value pairs in the map. Uses the stdlib `iter`
+// package so this function can be Used in a standard `for` loop.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(k K, v V) bool) {
+		for s := range m.liveSlots() {
+			if !yield(s.key, s.value) {
+				return
 			}
 		}
 	}
@@ -405,12 +463,9 @@ func (m *Map[K, V]) Keys() iter.Seq[K] {
 // this function can be Used in a standard `for` loop.
 func (m *Map[K, V]) Vals() iter.Seq[V] {
 	return func(yield func(v V) bool) {
-		for i := range m.groups {
-			for j := range m.groups[i].slots {
-				if m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
-					!yield(m.groups[i].slots[j].value) {
-					return
-				}
+		for s := range m.liveSlots() {
+			if !yield(s.value) {
+				return
 			}
 		}
 	}
@@ -421,10 +476,29 @@ func (m *Map[K, V]) Vals() iter.Seq[V] {
 // and the results will be seen by the hash map.
 func (m *Map[K, V]) PntrVals() iter.Seq[*V] {
 	return func(yield func(v *V) bool) {
+		for s := range m.liveSlots() {
+			if !yield(&s.value) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over all of the slots in the map that hold a value. The iteration
+// starts at the group and slot given by [iterStart], which is always the first
+// slot unless the sbmap_randiter build tag is supplied.
+func (m *Map[K, V]) liveSlots() iter.Seq[*slot[K, V]] {
+	return func(yield func(s *slot[K, V]) bool) {
+		startGroup, startSlot := iterStart(len(m.groups))
 		for i := range m.groups {
-			for j := range m.groups[i].slots {
-				if m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
-					!yield(&m.groups[i].slots[j].value) {
+			groupIdx := startGroup + i
+			if groupIdx >= len(m.groups) {
+				groupIdx -= len(m.groups)
+			}
+			for j := range slotprobes.GroupSize {
+				slotIdx := (startSlot + j) & (slotprobes.GroupSize - 1)
+				if m.groups[groupIdx].flags[slotIdx]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
+					!yield(&m.groups[groupIdx].slots[slotIdx]) {
 					return
 				}
 			}
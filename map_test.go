@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime/pprof"
 	"slices"
+	"strconv"
 	"testing"
 
 	sbtest "github.com/barbell-math/smoothbrain-test"
@@ -27,6 +28,80 @@ func TestSplitHash(t *testing.T) {
 	sbtest.Eq(t, slot, 0b1111110)
 }
 
+func TestSeededLayoutsAreReproducible(t *testing.T) {
+	layout := func(seed uint64) ([]uint8, []string) {
+		h := NewSeeded[string, int](_defaultInitialCap, seed)
+		for i := range 1000 {
+			h.Put(strconv.Itoa(i), i)
+		}
+		for i := 0; i < 1000; i += 3 {
+			h.Remove(strconv.Itoa(i))
+		}
+
+		slotKeys := []uint8{}
+		keys := []string{}
+		for i := range h.groups {
+			slotKeys = append(slotKeys, h.groups[i].slotKeys[:]...)
+			for j := range h.groups[i].slots {
+				keys = append(keys, h.groups[i].slots[j].key)
+			}
+		}
+		return slotKeys, keys
+	}
+
+	slotKeys1, keys1 := layout(1)
+	slotKeys2, keys2 := layout(1)
+	sbtest.Eq(t, slotKeys1, slotKeys2)
+	sbtest.Eq(t, keys1, keys2)
+
+	slotKeys3, keys3 := layout(2)
+	sbtest.False(t, slices.Equal(keys1, keys3) && slices.Equal(slotKeys1, slotKeys3))
+}
+
+func TestComparableHashSeed(t *testing.T) {
+	sbtest.Eq(t, ComparableHash[int]()(5), ComparableHashSeed[int](0)(5))
+	sbtest.Eq(t, ComparableHash[string]()("a"), ComparableHashSeed[string](0)("a"))
+	sbtest.Eq(t, ComparableHashSeed[int](7)(5), ComparableHashSeed[int](7)(5))
+	sbtest.True(t, ComparableHashSeed[int](7)(5) != ComparableHashSeed[int](8)(5))
+	sbtest.True(
+		t, ComparableHashSeed[string](7)("a") != ComparableHashSeed[string](8)("a"),
+	)
+}
+
+func TestComparableMixedHash(t *testing.T) {
+	identity := New[uint64, uint64]()
+	mixed := NewCustom[uint64, uint64](
+		_defaultInitialCap, ComparableEqual[uint64], ComparableMixedHash[uint64](3),
+	)
+
+	identitySlotHashes := map[uint8]struct{}{}
+	mixedSlotHashes := map[uint8]struct{}{}
+	for i := range uint64(1000) {
+		_, slotHash := identity.splitHash(identity.hash(i * 128))
+		identitySlotHashes[slotHash] = struct{}{}
+		_, slotHash = mixed.splitHash(mixed.hash(i * 128))
+		mixedSlotHashes[slotHash] = struct{}{}
+
+		mixed.Put(i*128, i)
+	}
+	// Multiples of 128 all share the same low 7 bits
+	sbtest.Eq(t, 1, len(identitySlotHashes))
+	sbtest.Eq(t, 128, len(mixedSlotHashes))
+
+	for i := range uint64(1000) {
+		val, ok := mixed.Get(i * 128)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+
+	sbtest.True(
+		t, ComparableMixedHash[int](1)(5) != ComparableMixedHash[int](2)(5),
+	)
+	sbtest.Eq(
+		t, ComparableHashSeed[string](1)("a"), ComparableMixedHash[string](1)("a"),
+	)
+}
+
 func TestHashMapPut(t *testing.T) {
 	h := New[int8, int16]()
 
@@ -61,6 +136,64 @@ func TestHashMapGet(t *testing.T) {
 	sbtest.Eq(t, 0, val)
 }
 
+// A view of a path as its individual segments, the segments are joined with
+// '/' to get the actual key.
+type pathSegments [][]byte
+
+func (p pathSegments) hash() uint64 {
+	var h maphash.Hash
+	h.SetSeed(_comparableSeed)
+	for i, s := range p {
+		if i > 0 {
+			h.WriteByte('/')
+		}
+		h.Write(s)
+	}
+	return h.Sum64()
+}
+
+func (p pathSegments) equal(k string) bool {
+	for i, s := range p {
+		if i > 0 {
+			if len(k) == 0 || k[0] != '/' {
+				return false
+			}
+			k = k[1:]
+		}
+		if len(k) < len(s) || k[:len(s)] != string(s) {
+			return false
+		}
+		k = k[len(s):]
+	}
+	return len(k) == 0
+}
+
+func TestGetWith(t *testing.T) {
+	h := New[string, int]()
+	for i := range 100 {
+		h.Put("api/v1/"+strconv.Itoa(i), i)
+	}
+
+	for i := range 100 {
+		q := pathSegments{[]byte("api"), []byte("v1"), []byte(strconv.Itoa(i))}
+		val, ok := GetWith(&h, q, pathSegments.hash, pathSegments.equal)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+
+	val, ok := GetWith(
+		&h, pathSegments{[]byte("api"), []byte("v1")},
+		pathSegments.hash, pathSegments.equal,
+	)
+	sbtest.False(t, ok)
+	sbtest.Eq(t, 0, val)
+	_, ok = GetWith(
+		&h, pathSegments{[]byte("api"), []byte("v1"), []byte("100")},
+		pathSegments.hash, pathSegments.equal,
+	)
+	sbtest.False(t, ok)
+}
+
 func TestHashMapRemove(t *testing.T) {
 	h := New[int8, int16]()
 
@@ -85,6 +218,23 @@ func TestHashMapRemove(t *testing.T) {
 	sbtest.Eq(t, _defaultInitialCap, cap(h.groups))
 }
 
+func TestReserve(t *testing.T) {
+	h := New[int, int]()
+	h.Reserve(1000)
+	_cap := cap(h.groups)
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+	sbtest.Eq(t, _cap, cap(h.groups))
+	h.Put(1000, 1000)
+	sbtest.Eq(t, _cap, cap(h.groups))
+
+	h.Reserve(0)
+	sbtest.Eq(t, _cap, cap(h.groups))
+	h.Reserve(1)
+	sbtest.Eq(t, _cap, cap(h.groups))
+}
+
 func TestHashMapClear(t *testing.T) {
 	h := New[int8, int16]()
 
@@ -189,8 +339,8 @@ func TestLargeishDataset(t *testing.T) {
 	pprof.StartCPUProfile(f)
 	defer pprof.StopCPUProfile()
 
-	op := func() {
-		h := New[int32, int64]()
+	op := func(seed uint64) {
+		h := NewSeeded[int32, int64](_defaultInitialCap, seed)
 
 		randVals := rand.New(rand.NewSource(3))
 		for i := 0; i < 10000; i++ {
@@ -225,10 +375,27 @@ func TestLargeishDataset(t *testing.T) {
 		}
 	}
 
+	seeds := rand.New(rand.NewSource(3))
 	for i := 0; i < 10; i++ {
 		// Testing with different hash seed values but with the same set of
 		// values to produce different map structures
-		_comparableSeed = maphash.MakeSeed()
-		op()
+		op(seeds.Uint64())
+	}
+}
+
+func TestAll(t *testing.T) {
+	h := New[int8, int16]()
+
+	h.Put(1, 1)
+	h.Put(2, 2)
+	h.Put(3, 3)
+	sbtest.Eq(t, 3, h.Len())
+	sbtest.Eq(t, _defaultInitialCap, cap(h.groups))
+
+	cntr := 0
+	for k, v := range h.All() {
+		sbtest.Eq(t, int16(k), v)
+		cntr++
 	}
+	sbtest.Eq(t, 3, cntr)
 }
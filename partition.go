@@ -0,0 +1,76 @@
+package sbmap
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// Splits the map into at most `n` disjoint partitions that together contain
+// every key, value pair in the map. Each partition may be iterated on its own
+// goroutine as long as the map is not modified while any partition is being
+// iterated. Fewer than `n` partitions are returned if the map does not have
+// enough groups to split between them.
+func (m *Map[K, V]) Partitions(n int) []iter.Seq2[K, V] {
+	n = max(1, min(n, len(m.groups)))
+	rv := make([]iter.Seq2[K, V], n)
+	for i := range n {
+		rv[i] = m.groupRange(i*len(m.groups)/n, (i+1)*len(m.groups)/n)
+	}
+	return rv
+}
+
+func (m *Map[K, V]) groupRange(start int, end int) iter.Seq2[K, V] {
+	groups := m.groups[start:end]
+	return func(yield func(k K, v V) bool) {
+		for i := range groups {
+			for j := range groups[i].slots {
+				if groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 &&
+					!yield(groups[i].slots[j].key, groups[i].slots[j].value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Calls `fn` for every key, value pair in the map using `workers` goroutines,
+// each iterating over its own partition of the map. If `fn` returns an error
+// the remaining workers stop and the first error is returned. If `ctx` is
+// cancelled the workers stop and the contexts error is returned. The map must
+// not be modified until ParallelRange returns.
+func (m *Map[K, V]) ParallelRange(
+	ctx context.Context,
+	workers int,
+	fn func(k K, v V) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var rvErr error
+	for _, p := range m.Partitions(workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k, v := range p {
+				err := ctx.Err()
+				if err == nil {
+					err = fn(k, v)
+				}
+				if err != nil {
+					once.Do(func() {
+						rvErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return rvErr
+}
@@ -0,0 +1,86 @@
+package sbmap
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestPartitions(t *testing.T) {
+	h := New[int, int]()
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+
+	for _, n := range []int{0, 1, 3, 8, 1 << 20} {
+		parts := h.Partitions(n)
+		sbtest.True(t, len(parts) >= 1)
+		sbtest.True(t, len(parts) <= max(n, 1))
+
+		seen := map[int]int{}
+		for _, p := range parts {
+			for k, v := range p {
+				sbtest.Eq(t, k, v)
+				seen[k]++
+			}
+		}
+		sbtest.Eq(t, 1000, len(seen))
+		for _, cnt := range seen {
+			sbtest.Eq(t, 1, cnt)
+		}
+	}
+}
+
+func TestParallelRange(t *testing.T) {
+	h := New[int, int]()
+	for i := range 10000 {
+		h.Put(i, i)
+	}
+
+	var sum atomic.Int64
+	err := h.ParallelRange(context.Background(), 4, func(k int, v int) error {
+		sum.Add(int64(v))
+		return nil
+	})
+	sbtest.Eq(t, nil, err)
+	sbtest.Eq(t, int64(10000*9999/2), sum.Load())
+}
+
+func TestParallelRangeError(t *testing.T) {
+	h := New[int, int]()
+	for i := range 10000 {
+		h.Put(i, i)
+	}
+
+	testErr := errors.New("test error")
+	var cntr atomic.Int64
+	err := h.ParallelRange(context.Background(), 4, func(k int, v int) error {
+		if cntr.Add(1) == 100 {
+			return testErr
+		}
+		return nil
+	})
+	sbtest.True(t, errors.Is(err, testErr))
+	sbtest.True(t, cntr.Load() < 10000)
+}
+
+func TestParallelRangeCancel(t *testing.T) {
+	h := New[int, int]()
+	for i := range 10000 {
+		h.Put(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var cntr atomic.Int64
+	err := h.ParallelRange(ctx, 4, func(k int, v int) error {
+		if cntr.Add(1) == 100 {
+			cancel()
+		}
+		return nil
+	})
+	sbtest.True(t, errors.Is(err, context.Canceled))
+	sbtest.True(t, cntr.Load() < 10000)
+}
@@ -0,0 +1,339 @@
+package sbmap
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+type (
+	// An entry in a persistent node. An entry is either a key, value pair or,
+	// when node is not nil, a pointer to the next level of the trie.
+	persistentEntry[K any, V any] struct {
+		hash  uint64
+		key   K
+		value V
+		node  *persistentNode[K, V]
+	}
+
+	// A node of a hash array mapped trie. Each level of the trie consumes
+	// _persistentBits bits of the hash, and the bitmap records which of the
+	// possible children are present so that entries only holds present
+	// children. Once every bit of the hash has been consumed a node is a
+	// collision node, and entries is a plain list of keys that share the same
+	// hash.
+	persistentNode[K any, V any] struct {
+		bitmap  uint32
+		entries []persistentEntry[K, V]
+	}
+
+	// An immutable map. Changing a PersistentMap with [PersistentMap.With] or
+	// [PersistentMap.Without] returns a new version of the map and leaves the
+	// original unchanged. The new version shares all of the unchanged parts of
+	// the original so each change only copies O(log n) data, making it cheap to
+	// keep old versions of a map around.
+	//
+	// Because versions are never modified they are safe for concurrent use.
+	PersistentMap[K any, V any] struct {
+		root *persistentNode[K, V]
+		len  int
+		eq   func(l K, r K) bool
+		hash func(v K) uint64
+	}
+)
+
+const (
+	_persistentBits = 5
+	_persistentMask = 1<<_persistentBits - 1
+)
+
+// Creates an empty PersistentMap where K is the key type and V is the value
+// type. [ComparableEqual] and [ComparableHash] functions will be Used by the
+// returned map.
+func NewPersistent[K comparable, V any]() PersistentMap[K, V] {
+	return NewCustomPersistent[K, V](ComparableEqual[K], ComparableHash[K]())
+}
+
+// Creates an empty PersistentMap where K is the key type and V is the value
+// type. The supplied `eq` and `hash` functions will be Used by the map, refer
+// to [NewCustom] for their requirements.
+func NewCustomPersistent[K any, V any](
+	eq func(l K, r K) bool,
+	hash func(v K) uint64,
+) PersistentMap[K, V] {
+	return PersistentMap[K, V]{eq: eq, hash: hash}
+}
+
+// Returns the number of elements in the map.
+func (m PersistentMap[K, V]) Len() int {
+	return m.len
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned. If the key
+// is not found the boolean return value will be false and a zero-initialized
+// value of type V will be returned.
+func (m PersistentMap[K, V]) Get(k K) (V, bool) {
+	h := m.hash(k)
+	node := m.root
+	for shift := 0; node != nil; shift += _persistentBits {
+		if shift >= 64 {
+			for _, e := range node.entries {
+				if e.hash == h && m.eq(e.key, k) {
+					return e.value, true
+				}
+			}
+			break
+		}
+
+		bit := uint32(1) << ((h >> shift) & _persistentMask)
+		if node.bitmap&bit == 0 {
+			break
+		}
+		e := &node.entries[bits.OnesCount32(node.bitmap&(bit-1))]
+		if e.node == nil {
+			if e.hash == h && m.eq(e.key, k) {
+				return e.value, true
+			}
+			break
+		}
+		node = e.node
+	}
+	var tmp V
+	return tmp, false
+}
+
+// Returns a new version of the map with the supplied key, value pair placed in
+// it. If the key was already present the new version will have the new value.
+// The original map is not changed.
+func (m PersistentMap[K, V]) With(k K, v V) PersistentMap[K, V] {
+	leaf := persistentEntry[K, V]{hash: m.hash(k), key: k, value: v}
+	root, added := m.with(m.root, 0, leaf)
+	rv := m
+	rv.root = root
+	if added {
+		rv.len++
+	}
+	return rv
+}
+
+func (m PersistentMap[K, V]) with(
+	node *persistentNode[K, V],
+	shift int,
+	leaf persistentEntry[K, V],
+) (*persistentNode[K, V], bool) {
+	if node == nil {
+		return newPersistentNode(shift, leaf), true
+	}
+	if shift >= 64 {
+		rv := &persistentNode[K, V]{entries: slices.Clone(node.entries)}
+		for i, e := range rv.entries {
+			if m.eq(e.key, leaf.key) {
+				rv.entries[i] = leaf
+				return rv, false
+			}
+		}
+		rv.entries = append(rv.entries, leaf)
+		return rv, true
+	}
+
+	bit := uint32(1) << ((leaf.hash >> shift) & _persistentMask)
+	pos := bits.OnesCount32(node.bitmap & (bit - 1))
+	if node.bitmap&bit == 0 {
+		return &persistentNode[K, V]{
+			bitmap:  node.bitmap | bit,
+			entries: slices.Insert(slices.Clone(node.entries), pos, leaf),
+		}, true
+	}
+
+	rv := &persistentNode[K, V]{
+		bitmap:  node.bitmap,
+		entries: slices.Clone(node.entries),
+	}
+	e := &rv.entries[pos]
+	switch {
+	case e.node != nil:
+		child, added := m.with(e.node, shift+_persistentBits, leaf)
+		e.node = child
+		return rv, added
+	case e.hash == leaf.hash && m.eq(e.key, leaf.key):
+		*e = leaf
+		return rv, false
+	default:
+		*e = persistentEntry[K, V]{
+			node: mergePersistentLeaves(shift+_persistentBits, *e, leaf),
+		}
+		return rv, true
+	}
+}
+
+// Creates a node that only holds the supplied leaf.
+func newPersistentNode[K any, V any](
+	shift int,
+	leaf persistentEntry[K, V],
+) *persistentNode[K, V] {
+	if shift >= 64 {
+		return &persistentNode[K, V]{entries: []persistentEntry[K, V]{leaf}}
+	}
+	return &persistentNode[K, V]{
+		bitmap:  uint32(1) << ((leaf.hash >> shift) & _persistentMask),
+		entries: []persistentEntry[K, V]{leaf},
+	}
+}
+
+// Creates the nodes needed to hold two leaves whose hashes matched for all
+// levels above the supplied shift.
+func mergePersistentLeaves[K any, V any](
+	shift int,
+	l persistentEntry[K, V],
+	r persistentEntry[K, V],
+) *persistentNode[K, V] {
+	if shift >= 64 {
+		return &persistentNode[K, V]{entries: []persistentEntry[K, V]{l, r}}
+	}
+
+	lIdx := (l.hash >> shift) & _persistentMask
+	rIdx := (r.hash >> shift) & _persistentMask
+	if lIdx == rIdx {
+		return &persistentNode[K, V]{
+			bitmap: uint32(1) << lIdx,
+			entries: []persistentEntry[K, V]{
+				{node: mergePersistentLeaves(shift+_persistentBits, l, r)},
+			},
+		}
+	}
+	if lIdx > rIdx {
+		l, r = r, l
+	}
+	return &persistentNode[K, V]{
+		bitmap:  uint32(1)<<lIdx | uint32(1)<<rIdx,
+		entries: []persistentEntry[K, V]{l, r},
+	}
+}
+
+// Returns a new version of the map with the supplied key removed. If the key
+// is not present the original map is returned. The original map is not
+// changed.
+func (m PersistentMap[K, V]) Without(k K) PersistentMap[K, V] {
+	root, removed := m.without(m.root, 0, m.hash(k), k)
+	if !removed {
+		return m
+	}
+	rv := m
+	rv.root = root
+	rv.len--
+	return rv
+}
+
+func (m PersistentMap[K, V]) without(
+	node *persistentNode[K, V],
+	shift int,
+	h uint64,
+	k K,
+) (*persistentNode[K, V], bool) {
+	if node == nil {
+		return nil, false
+	}
+	if shift >= 64 {
+		for i, e := range node.entries {
+			if e.hash == h && m.eq(e.key, k) {
+				return persistentNodeWithout(node, 0, i), true
+			}
+		}
+		return node, false
+	}
+
+	bit := uint32(1) << ((h >> shift) & _persistentMask)
+	if node.bitmap&bit == 0 {
+		return node, false
+	}
+	pos := bits.OnesCount32(node.bitmap & (bit - 1))
+	e := node.entries[pos]
+	if e.node == nil {
+		if e.hash == h && m.eq(e.key, k) {
+			return persistentNodeWithout(node, bit, pos), true
+		}
+		return node, false
+	}
+
+	child, removed := m.without(e.node, shift+_persistentBits, h, k)
+	if !removed {
+		return node, false
+	}
+	if child == nil {
+		return persistentNodeWithout(node, bit, pos), true
+	}
+	rv := &persistentNode[K, V]{
+		bitmap:  node.bitmap,
+		entries: slices.Clone(node.entries),
+	}
+	if len(child.entries) == 1 && child.entries[0].node == nil {
+		// A child holding a single leaf is pulled up into this node so the
+		// trie does not keep chains of single entry nodes
+		rv.entries[pos] = child.entries[0]
+	} else {
+		rv.entries[pos].node = child
+	}
+	return rv, true
+}
+
+// Returns a copy of the supplied node without the entry at `pos`, or nil if the
+// node would be empty.
+func persistentNodeWithout[K any, V any](
+	node *persistentNode[K, V],
+	bit uint32,
+	pos int,
+) *persistentNode[K, V] {
+	if len(node.entries) == 1 {
+		return nil
+	}
+	return &persistentNode[K, V]{
+		bitmap:  node.bitmap &^ bit,
+		entries: slices.Delete(slices.Clone(node.entries), pos, pos+1),
+	}
+}
+
+// Iterates over all of the keys in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (m PersistentMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		m.root.walk(func(e *persistentEntry[K, V]) bool { return yield(e.key) })
+	}
+}
+
+// Iterates over all of the key, value pairs in the map. Uses the stdlib `iter`
+// package so this function can be Used in a standard `for` loop.
+func (m PersistentMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(k K, v V) bool) {
+		m.root.walk(func(e *persistentEntry[K, V]) bool {
+			return yield(e.key, e.value)
+		})
+	}
+}
+
+// Iterates over all of the values in the map. Uses the stdlib `iter` package so
+// this function can be Used in a standard `for` loop.
+func (m PersistentMap[K, V]) Vals() iter.Seq[V] {
+	return func(yield func(v V) bool) {
+		m.root.walk(func(e *persistentEntry[K, V]) bool { return yield(e.value) })
+	}
+}
+
+// Calls `fn` with every leaf below the node, stopping early if `fn` returns
+// false. Returns false if the walk was stopped early.
+func (n *persistentNode[K, V]) walk(fn func(e *persistentEntry[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	for i := range n.entries {
+		e := &n.entries[i]
+		if e.node != nil {
+			if !e.node.walk(fn) {
+				return false
+			}
+		} else if !fn(e) {
+			return false
+		}
+	}
+	return true
+}
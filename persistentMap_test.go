@@ -0,0 +1,110 @@
+package sbmap
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func checkPersistentMap[K comparable, V any](
+	t *testing.T,
+	m PersistentMap[K, V],
+	exp map[K]V,
+) {
+	t.Helper()
+	sbtest.Eq(t, len(exp), m.Len())
+	for k, v := range exp {
+		val, ok := m.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, v, val)
+	}
+	sbtest.Eq(t, exp, maps.Collect(m.All()))
+}
+
+func TestPersistentMapVersions(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	m := NewPersistent[int32, int32]()
+	exp := map[int32]int32{}
+	versions := []PersistentMap[int32, int32]{m}
+	expVersions := []map[int32]int32{maps.Clone(exp)}
+
+	for i := range 5000 {
+		k := r.Int31n(1000)
+		if r.Intn(3) == 0 {
+			m = m.Without(k)
+			delete(exp, k)
+		} else {
+			m = m.With(k, int32(i))
+			exp[k] = int32(i)
+		}
+		if i%100 == 0 {
+			versions = append(versions, m)
+			expVersions = append(expVersions, maps.Clone(exp))
+		}
+	}
+	checkPersistentMap(t, m, exp)
+	// Old versions are not changed by later changes
+	for i := range versions {
+		checkPersistentMap(t, versions[i], expVersions[i])
+	}
+}
+
+func TestPersistentMapWithout(t *testing.T) {
+	m := NewPersistent[int, int]()
+	for i := range 1000 {
+		m = m.With(i, i)
+	}
+	m2 := m.Without(1000)
+	sbtest.Eq(t, 1000, m2.Len())
+	sbtest.True(t, m.root == m2.root)
+
+	for i := range 1000 {
+		m = m.Without(i)
+	}
+	sbtest.Eq(t, 0, m.Len())
+	sbtest.True(t, m.root == nil)
+	_, ok := m.Get(1)
+	sbtest.False(t, ok)
+}
+
+func TestPersistentMapCollisions(t *testing.T) {
+	// Every key shares one of four hashes, so the trie is filled with
+	// collision nodes
+	m := NewCustomPersistent[int, string](
+		ComparableEqual[int],
+		func(v int) uint64 { return uint64(v % 4) },
+	)
+	exp := map[int]string{}
+	for i := range 100 {
+		m = m.With(i, "a")
+		exp[i] = "a"
+	}
+	m = m.With(5, "b")
+	exp[5] = "b"
+	checkPersistentMap(t, m, exp)
+
+	for i := 0; i < 100; i += 2 {
+		m = m.Without(i)
+		delete(exp, i)
+	}
+	checkPersistentMap(t, m, exp)
+	_, ok := m.Get(2)
+	sbtest.False(t, ok)
+}
+
+func TestPersistentMapIterStopsEarly(t *testing.T) {
+	m := NewPersistent[int, int]()
+	for i := range 100 {
+		m = m.With(i, i)
+	}
+	cntr := 0
+	for range m.Keys() {
+		cntr++
+		if cntr == 10 {
+			break
+		}
+	}
+	sbtest.Eq(t, 10, cntr)
+}
@@ -0,0 +1,78 @@
+package sbmap
+
+import (
+	"math/rand"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// Returns a key chosen uniformly at random from the map. The boolean return
+// value will be false if the map is empty.
+//
+// Random slots are picked until a slot holding a live key is found, which
+// keeps every key equally likely regardless of how keys are spread across the
+// groups. The expected number of picks is the maps capacity divided by its
+// length, so picking from a map that is much larger than its length, such as
+// one created with [NewCap] or grown with [Map.Reserve], takes more picks.
+func (m *Map[K, V]) RandomKey(r *rand.Rand) (K, bool) {
+	if m.Len() == 0 {
+		var tmp K
+		return tmp, false
+	}
+	groupIdx, slotIdx := m.randomSlot(r)
+	return m.groups[groupIdx].slots[slotIdx].key, true
+}
+
+// Returns up to `n` distinct keys chosen uniformly at random from the map. If
+// `n` is greater than or equal to the length of the map all keys are returned
+// in a random order.
+func (m *Map[K, V]) Sample(n int, r *rand.Rand) []K {
+	n = min(n, m.Len())
+	if n <= 0 {
+		return []K{}
+	}
+
+	rv := make([]K, 0, n)
+	if n > m.Len()/2 {
+		// Picking random slots slows down as more keys are picked, so when
+		// most of the keys are needed walk the map with reservoir sampling
+		// instead.
+		i := 0
+		for k := range m.Keys() {
+			if len(rv) < n {
+				rv = append(rv, k)
+			} else if j := r.Intn(i + 1); j < n {
+				rv[j] = k
+			}
+			i++
+		}
+		r.Shuffle(len(rv), func(i, j int) { rv[i], rv[j] = rv[j], rv[i] })
+		return rv
+	}
+
+	seen := New[uint64, struct{}]()
+	for len(rv) < n {
+		groupIdx, slotIdx := m.randomSlot(r)
+		pos := groupIdx*slotprobes.GroupSize + uint64(slotIdx)
+		if _, ok := seen.Get(pos); ok {
+			continue
+		}
+		seen.Put(pos, struct{}{})
+		rv = append(rv, m.groups[groupIdx].slots[slotIdx].key)
+	}
+	return rv
+}
+
+// Picks slots uniformly at random until a live slot is found. The map must not
+// be empty.
+func (m *Map[K, V]) randomSlot(r *rand.Rand) (uint64, int) {
+	numSlots := int64(len(m.groups)) * slotprobes.GroupSize
+	for {
+		pos := r.Int63n(numSlots)
+		groupIdx := uint64(pos / slotprobes.GroupSize)
+		slotIdx := int(pos % slotprobes.GroupSize)
+		if m.groups[groupIdx].flags[slotIdx]&(slotprobes.Used|slotprobes.Deleted) == 0b1 {
+			return groupIdx, slotIdx
+		}
+	}
+}
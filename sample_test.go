@@ -0,0 +1,114 @@
+package sbmap
+
+import (
+	"math/rand"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+// The critical values of the chi-squared distribution at a significance level
+// of 0.001, indexed by the degrees of freedom.
+var _chiSquared001 = map[int]float64{8: 26.12, 9: 27.88}
+
+func chiSquared(counts map[int]int, expected float64) float64 {
+	rv := 0.0
+	for _, c := range counts {
+		rv += (float64(c) - expected) * (float64(c) - expected) / expected
+	}
+	return rv
+}
+
+func TestRandomKeyEmpty(t *testing.T) {
+	h := New[int, int]()
+	_, ok := h.RandomKey(rand.New(rand.NewSource(3)))
+	sbtest.False(t, ok)
+	sbtest.Eq(t, []int{}, h.Sample(10, rand.New(rand.NewSource(3))))
+}
+
+func TestRandomKeySparseIsUnbiased(t *testing.T) {
+	// Keys 0-7 share a group while key 1<<20 is alone in another group, picking
+	// a random group first would select key 1<<20 half of the time.
+	h := NewCap[int, int](1024)
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7, 1 << 20}
+	for _, k := range keys {
+		h.Put(k, k)
+	}
+
+	r := rand.New(rand.NewSource(3))
+	counts := map[int]int{}
+	const iters = 90000
+	for range iters {
+		k, ok := h.RandomKey(r)
+		sbtest.True(t, ok)
+		counts[k]++
+	}
+	sbtest.Eq(t, len(keys), len(counts))
+	sbtest.True(
+		t,
+		chiSquared(counts, float64(iters)/float64(len(keys))) < _chiSquared001[len(keys)-1],
+	)
+}
+
+func TestRandomKeyDenseIsUnbiased(t *testing.T) {
+	h := New[int, int]()
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7, 1 << 20}
+	for _, k := range keys {
+		h.Put(k, k)
+	}
+
+	r := rand.New(rand.NewSource(3))
+	counts := map[int]int{}
+	const iters = 90000
+	for range iters {
+		k, ok := h.RandomKey(r)
+		sbtest.True(t, ok)
+		counts[k]++
+	}
+	sbtest.Eq(t, len(keys), len(counts))
+	sbtest.True(
+		t,
+		chiSquared(counts, float64(iters)/float64(len(keys))) < _chiSquared001[len(keys)-1],
+	)
+}
+
+func TestSampleIsUnbiased(t *testing.T) {
+	h := NewCap[int, int](1024)
+	for i := range 10 {
+		h.Put(i*1000, i)
+	}
+	// Tombstones must never be sampled
+	h.Put(-1, -1)
+	h.Remove(-1)
+
+	for _, n := range []int{3, 7} {
+		r := rand.New(rand.NewSource(3))
+		counts := map[int]int{}
+		const iters = 20000
+		for range iters {
+			sample := h.Sample(n, r)
+			sbtest.Eq(t, n, len(sample))
+			distinct := map[int]struct{}{}
+			for _, k := range sample {
+				distinct[k] = struct{}{}
+				counts[k]++
+			}
+			sbtest.Eq(t, n, len(distinct))
+		}
+		sbtest.Eq(t, 10, len(counts))
+		sbtest.True(
+			t,
+			chiSquared(counts, float64(iters*n)/10) < _chiSquared001[9],
+		)
+	}
+}
+
+func TestSampleMoreThanLen(t *testing.T) {
+	h := New[int, int]()
+	for i := range 5 {
+		h.Put(i, i)
+	}
+	sbtest.SlicesMatchUnordered(
+		t, []int{0, 1, 2, 3, 4}, h.Sample(10, rand.New(rand.NewSource(3))),
+	)
+}
@@ -0,0 +1,73 @@
+package sbmap
+
+import (
+	"math/bits"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// Incrementally iterates over the keys in the map, similar to the Redis SCAN
+// command. Start a scan with a cursor of zero and pass the returned cursor to
+// the next call. The scan is complete when the returned cursor is zero.
+// `count` is a hint for how many keys to return per call, at least `count` keys
+// are returned unless the scan is complete.
+//
+// Every key that is present in the map for the entire duration of the scan is
+// returned at least once, even if the map grows or shrinks between calls. Keys
+// may be returned more than once and keys that are added or removed during the
+// scan may or may not be returned.
+//
+// The guarantee holds because the cursor walks the groups that keys hash to
+// rather than the groups they are stored in, and it does so by incrementing the
+// reversed bits of the cursor. When the map is resized the keys of a group are
+// split between, or merged from, groups that share the low bits of the cursor.
+// Incrementing the high bits first means those groups are either all visited
+// already or all still to be visited.
+func (m *Map[K, V]) Scan(cursor uint64, count int) ([]K, uint64) {
+	count = max(count, 1)
+	mask := uint64(cap(m.groups)) - 1
+	// A hint larger than the map, such as math.MaxInt to mean every key,
+	// only needs room for the keys that are in the map
+	keys := make([]K, 0, min(count, m.Len()))
+
+	for {
+		keys = m.scanHomeGroup(cursor&mask, keys)
+
+		cursor |= ^mask
+		cursor = bits.Reverse64(bits.Reverse64(cursor) + 1)
+		if cursor == 0 || len(keys) >= count {
+			return keys, cursor
+		}
+	}
+}
+
+// Appends all keys that hash to the supplied group to `keys`. Keys are not
+// always stored in the group that they hash to, so the groups are walked in the
+// same order that [Map.Put] walks them until a group with an empty slot is
+// found. Any key that hashes to the supplied group cannot be placed past that
+// group.
+func (m *Map[K, V]) scanHomeGroup(homeGroup uint64, keys []K) []K {
+	groupHash := homeGroup
+	doubleHash := m.doubleHash(groupHash)
+
+	for i := uint64(1); ; i++ {
+		hasEmpty := false
+		for j := range m.groups[groupHash].slots {
+			switch m.groups[groupHash].flags[j] & (slotprobes.Used | slotprobes.Deleted) {
+			case 0:
+				hasEmpty = true
+			case slotprobes.Used:
+				k := m.groups[groupHash].slots[j].key
+				keyGroupHash, _ := m.splitHash(m.hash(k))
+				if m.clampedGroupHash(keyGroupHash) == homeGroup {
+					keys = append(keys, k)
+				}
+			}
+		}
+		if hasEmpty {
+			return keys
+		}
+
+		groupHash = m.clampedGroupHash(groupHash + i*doubleHash)
+	}
+}
@@ -0,0 +1,86 @@
+package sbmap
+
+import (
+	"math"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestScan(t *testing.T) {
+	h := New[int, int]()
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+
+	seen := map[int]int{}
+	cursor := uint64(0)
+	for {
+		var keys []int
+		keys, cursor = h.Scan(cursor, 10)
+		for _, k := range keys {
+			seen[k]++
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	sbtest.Eq(t, 1000, len(seen))
+	for _, cnt := range seen {
+		sbtest.Eq(t, 1, cnt)
+	}
+}
+
+func TestScanHugeCount(t *testing.T) {
+	h := New[int, int]()
+	for i := range 100 {
+		h.Put(i, i)
+	}
+	keys, cursor := h.Scan(0, math.MaxInt)
+	sbtest.Eq(t, uint64(0), cursor)
+	sbtest.Eq(t, 100, len(keys))
+}
+
+func TestScanWhileResizing(t *testing.T) {
+	h := New[int, int]()
+	// Keys below 500 are present for the entire scan
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+
+	seen := map[int]struct{}{}
+	cursor := uint64(0)
+	calls := 0
+	for {
+		var keys []int
+		keys, cursor = h.Scan(cursor, 5)
+		for _, k := range keys {
+			seen[k] = struct{}{}
+		}
+		if cursor == 0 {
+			break
+		}
+
+		calls++
+		switch {
+		case calls < 20:
+			// Grow the map several times
+			for i := range 1000 {
+				h.Put(1000+calls*1000+i, i)
+			}
+		case calls < 40:
+			// Shrink the map several times
+			for i := range 1000 {
+				h.Remove(1000 + (calls-19)*1000 + i)
+			}
+		case calls == 40:
+			for i := 500; i < 1000; i++ {
+				h.Remove(i)
+			}
+		}
+	}
+	for i := range 500 {
+		_, ok := seen[i]
+		sbtest.True(t, ok)
+	}
+}
@@ -0,0 +1,272 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// A snapshot is a stream of little endian values laid out as follows:
+//
+//	header:
+//		magic        [8]byte  "SBMAPSNP"
+//		version      uint32   currently 1
+//		group size   uint32   the slotprobes.GroupSize of the writing build
+//		entry count  uint64
+//		checksum     uint32   CRC32 of the preceding header fields
+//	blocks, repeated until entry count entries have been read:
+//		entries      uint32   number of entries in the block, at least one
+//		length       uint32   number of payload bytes
+//		checksum     uint32   CRC32 of the entries, length, and payload
+//		payload      [length]byte
+//
+// The payload of a block is each key followed by its value, written with the
+// encoders supplied to [Map.WriteSnapshot]. All checksums use the Castagnoli
+// polynomial. The group size is recorded for diagnostics only, a snapshot only
+// holds keys and values so it can be read by a build with any group size.
+
+type (
+	// Returned by [Map.ReadSnapshot] when the snapshot is not valid. Err is
+	// one of the ErrSnapshot errors and Offset is the byte offset into the
+	// snapshot where the problem was found.
+	SnapshotError struct {
+		Offset int64
+		Err    error
+	}
+
+	// Returned by [Map.ReadSnapshot], wrapped in a [SnapshotError], when the
+	// snapshot was written with a version of the format that this build cannot
+	// read.
+	SnapshotVersionError struct {
+		Version uint32
+	}
+)
+
+const (
+	SnapshotVersion uint32 = 1
+
+	_snapshotMagic      = "SBMAPSNP"
+	_snapshotHeaderSize = 28
+	_snapshotBlockSize  = 12
+	// The number of payload bytes after which a block is written. Blocks can
+	// be larger than this by up to the size of one entry.
+	_snapshotBlockTarget = 64 * 1024
+	// The most entries that will be reserved in the map before reading them.
+	_snapshotMaxReserve = 1 << 24
+)
+
+var (
+	// The snapshot does not start with the snapshot magic bytes.
+	ErrSnapshotBadMagic = errors.New("sbmap: not a snapshot")
+	// The snapshot ended before all of its entries were read.
+	ErrSnapshotTruncated = errors.New("sbmap: snapshot is truncated")
+	// A header or block checksum did not match its data.
+	ErrSnapshotChecksum = errors.New("sbmap: snapshot checksum mismatch")
+	// A block is inconsistent with the header or with the supplied decoders,
+	// such as a block holding more entries than the header declared.
+	ErrSnapshotCorrupt = errors.New("sbmap: snapshot is corrupt")
+
+	_crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("%s (at byte offset %d)", e.Err, e.Offset)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SnapshotVersionError) Error() string {
+	return fmt.Sprintf(
+		"sbmap: unsupported snapshot version %d, expected %d",
+		e.Version, SnapshotVersion,
+	)
+}
+
+// Writes every key, value pair in the map to `w` in the snapshot format
+// described at the top of this file. Each key and value is written with `encK`
+// and `encV`, which must write exactly the bytes that the decoders given to
+// [Map.ReadSnapshot] will read. Entries are streamed in blocks so the whole
+// map is never buffered in memory. The map must not be modified while the
+// snapshot is being written.
+func (m *Map[K, V]) WriteSnapshot(
+	w io.Writer,
+	encK func(w io.Writer, k K) error,
+	encV func(w io.Writer, v V) error,
+) error {
+	header := make([]byte, 0, _snapshotHeaderSize)
+	header = append(header, _snapshotMagic...)
+	header = binary.LittleEndian.AppendUint32(header, SnapshotVersion)
+	header = binary.LittleEndian.AppendUint32(header, slotprobes.GroupSize)
+	header = binary.LittleEndian.AppendUint64(header, uint64(m.Len()))
+	header = binary.LittleEndian.AppendUint32(
+		header, crc32.Checksum(header, _crcTable),
+	)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	var block bytes.Buffer
+	entries := uint32(0)
+	flush := func() error {
+		if entries == 0 {
+			return nil
+		}
+		data := block.Bytes()
+		binary.LittleEndian.PutUint32(data[0:4], entries)
+		binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-_snapshotBlockSize))
+		crc := crc32.Update(0, _crcTable, data[0:8])
+		crc = crc32.Update(crc, _crcTable, data[_snapshotBlockSize:])
+		binary.LittleEndian.PutUint32(data[8:12], crc)
+		_, err := w.Write(data)
+		block.Reset()
+		entries = 0
+		return err
+	}
+
+	for k, v := range m.All() {
+		if entries == 0 {
+			// Space for the block header, it is filled in once the block
+			// is full
+			block.Write(make([]byte, _snapshotBlockSize))
+		}
+		if err := encK(&block, k); err != nil {
+			return err
+		}
+		if err := encV(&block, v); err != nil {
+			return err
+		}
+		entries++
+		if block.Len()-_snapshotBlockSize >= _snapshotBlockTarget ||
+			entries == math.MaxUint32 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// Reads a snapshot written by [Map.WriteSnapshot] from `r` and places every
+// entry in the map. Each key and value is read with `decK` and `decV`, which
+// must read exactly the bytes that the encoders wrote. Like a builtin map,
+// values that are already in the map are kept unless they are overwritten by a
+// snapshot value. The map is grown up front to fit the entries.
+//
+// Exactly the bytes of the snapshot are read from `r`, so a snapshot can be
+// followed by other data. Problems with the snapshot itself are returned as a
+// [SnapshotError], including a decoder reading past the end of a block.
+// Errors from `r` other than an unexpected end of file and other errors from
+// the decoders are returned as is. If the map was not created with a
+// constructor [ErrUninitializedMap] is returned. Entries read before an error
+// is found are left in the map.
+func (m *Map[K, V]) ReadSnapshot(
+	r io.Reader,
+	decK func(r io.Reader) (K, error),
+	decV func(r io.Reader) (V, error),
+) error {
+	if m.hash == nil || m.eq == nil {
+		return ErrUninitializedMap
+	}
+
+	offset := int64(0)
+	header := make([]byte, _snapshotHeaderSize)
+	if err := readSnapshotFull(r, header, offset); err != nil {
+		return err
+	}
+	if string(header[0:8]) != _snapshotMagic {
+		return &SnapshotError{Offset: offset, Err: ErrSnapshotBadMagic}
+	}
+	// The version is checked before the checksum because other versions may
+	// not have the checksum in the same place
+	if v := binary.LittleEndian.Uint32(header[8:12]); v != SnapshotVersion {
+		return &SnapshotError{
+			Offset: offset, Err: &SnapshotVersionError{Version: v},
+		}
+	}
+	if crc32.Checksum(header[:24], _crcTable) != binary.LittleEndian.Uint32(header[24:28]) {
+		return &SnapshotError{Offset: offset, Err: ErrSnapshotChecksum}
+	}
+	remaining := binary.LittleEndian.Uint64(header[16:24])
+	offset += _snapshotHeaderSize
+	// A crafted snapshot can have a valid checksum and a huge count, so the
+	// amount reserved up front is capped. The map grows as normal past it.
+	m.Reserve(int(min(remaining, _snapshotMaxReserve)))
+
+	blockHeader := make([]byte, _snapshotBlockSize)
+	var payload bytes.Buffer
+	for remaining > 0 {
+		if err := readSnapshotFull(r, blockHeader, offset); err != nil {
+			return err
+		}
+		entries := binary.LittleEndian.Uint32(blockHeader[0:4])
+		length := int64(binary.LittleEndian.Uint32(blockHeader[4:8]))
+
+		// The payload is read incrementally so that a corrupt length does
+		// not cause a huge allocation before the checksum is checked.
+		payload.Reset()
+		n, err := payload.ReadFrom(io.LimitReader(r, length))
+		if err != nil {
+			return err
+		}
+		if n < length {
+			return &SnapshotError{
+				Offset: offset + _snapshotBlockSize + n, Err: ErrSnapshotTruncated,
+			}
+		}
+		crc := crc32.Update(0, _crcTable, blockHeader[0:8])
+		crc = crc32.Update(crc, _crcTable, payload.Bytes())
+		if crc != binary.LittleEndian.Uint32(blockHeader[8:12]) {
+			return &SnapshotError{Offset: offset, Err: ErrSnapshotChecksum}
+		}
+		if entries == 0 || uint64(entries) > remaining {
+			return &SnapshotError{Offset: offset, Err: ErrSnapshotCorrupt}
+		}
+
+		blockReader := bytes.NewReader(payload.Bytes())
+		for range entries {
+			k, err := decK(blockReader)
+			if err != nil {
+				return snapshotDecodeErr(err, offset)
+			}
+			v, err := decV(blockReader)
+			if err != nil {
+				return snapshotDecodeErr(err, offset)
+			}
+			m.Put(k, v)
+		}
+		if blockReader.Len() != 0 {
+			return &SnapshotError{Offset: offset, Err: ErrSnapshotCorrupt}
+		}
+		remaining -= uint64(entries)
+		offset += _snapshotBlockSize + length
+	}
+	return nil
+}
+
+// Fills `b` from `r`, converting an early end of file into a truncation error.
+func readSnapshotFull(r io.Reader, b []byte, offset int64) error {
+	n, err := io.ReadFull(r, b)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &SnapshotError{
+			Offset: offset + int64(n), Err: ErrSnapshotTruncated,
+		}
+	}
+	return err
+}
+
+// The block has a valid checksum, so a decoder running out of data means the
+// decoders do not match the encoders that wrote the snapshot.
+func snapshotDecodeErr(err error, offset int64) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &SnapshotError{Offset: offset, Err: ErrSnapshotCorrupt}
+	}
+	return err
+}
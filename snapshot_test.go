@@ -0,0 +1,134 @@
+package sbmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func encSnapshotInt(w io.Writer, v int64) error {
+	return binary.Write(w, binary.LittleEndian, v)
+}
+
+func decSnapshotInt(r io.Reader) (int64, error) {
+	var rv int64
+	err := binary.Read(r, binary.LittleEndian, &rv)
+	return rv, err
+}
+
+func encSnapshotString(w io.Writer, v string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(v))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, v)
+	return err
+}
+
+func decSnapshotString(r io.Reader) (string, error) {
+	var l uint32
+	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+		return "", err
+	}
+	rv := make([]byte, l)
+	_, err := io.ReadFull(r, rv)
+	return string(rv), err
+}
+
+func writeTestSnapshot(t *testing.T, n int) []byte {
+	h := New[int64, string]()
+	for i := range int64(n) {
+		h.Put(i, strconv.Itoa(int(i)))
+	}
+	var buf bytes.Buffer
+	sbtest.Eq(t, nil, h.WriteSnapshot(&buf, encSnapshotInt, encSnapshotString))
+	return buf.Bytes()
+}
+
+func readTestSnapshot(data []byte) (Map[int64, string], error) {
+	h := New[int64, string]()
+	err := h.ReadSnapshot(bytes.NewReader(data), decSnapshotInt, decSnapshotString)
+	return h, err
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 50000} {
+		data := writeTestSnapshot(t, n)
+		h, err := readTestSnapshot(data)
+		sbtest.Eq(t, nil, err)
+		sbtest.Eq(t, n, h.Len())
+		for i := range int64(n) {
+			val, ok := h.Get(i)
+			sbtest.True(t, ok)
+			sbtest.Eq(t, strconv.Itoa(int(i)), val)
+		}
+	}
+}
+
+func TestSnapshotLeavesTrailingData(t *testing.T) {
+	data := append(writeTestSnapshot(t, 50000), "trailing"...)
+	r := bytes.NewReader(data)
+	h := New[int64, string]()
+	sbtest.Eq(t, nil, h.ReadSnapshot(r, decSnapshotInt, decSnapshotString))
+	rest, _ := io.ReadAll(r)
+	sbtest.Eq(t, "trailing", string(rest))
+}
+
+func TestSnapshotTruncated(t *testing.T) {
+	data := writeTestSnapshot(t, 10000)
+	for l := 0; l < len(data); l += 1 + len(data)/100 {
+		_, err := readTestSnapshot(data[:l])
+		sbtest.True(t, errors.Is(err, ErrSnapshotTruncated))
+		var snapErr *SnapshotError
+		sbtest.True(t, errors.As(err, &snapErr))
+		sbtest.Eq(t, int64(l), snapErr.Offset)
+	}
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	data := writeTestSnapshot(t, 10000)
+
+	corrupt := bytes.Clone(data)
+	corrupt[0] = 'X'
+	_, err := readTestSnapshot(corrupt)
+	sbtest.True(t, errors.Is(err, ErrSnapshotBadMagic))
+
+	corrupt = bytes.Clone(data)
+	corrupt[8] = 2
+	_, err = readTestSnapshot(corrupt)
+	var versionErr *SnapshotVersionError
+	sbtest.True(t, errors.As(err, &versionErr))
+	sbtest.Eq(t, uint32(2), versionErr.Version)
+
+	corrupt = bytes.Clone(data)
+	corrupt[16]++
+	_, err = readTestSnapshot(corrupt)
+	sbtest.True(t, errors.Is(err, ErrSnapshotChecksum))
+
+	for _, i := range []int{_snapshotHeaderSize, len(data) / 2, len(data) - 1} {
+		corrupt = bytes.Clone(data)
+		corrupt[i] ^= 0b100
+		_, err = readTestSnapshot(corrupt)
+		sbtest.True(t, errors.Is(err, ErrSnapshotChecksum) ||
+			errors.Is(err, ErrSnapshotTruncated))
+	}
+}
+
+func TestSnapshotMismatchedDecoders(t *testing.T) {
+	data := writeTestSnapshot(t, 100)
+	h := New[int64, int64]()
+	err := h.ReadSnapshot(bytes.NewReader(data), decSnapshotInt, decSnapshotInt)
+	sbtest.True(t, errors.Is(err, ErrSnapshotCorrupt))
+}
+
+func TestSnapshotUninitializedMap(t *testing.T) {
+	var h Map[int64, string]
+	err := h.ReadSnapshot(
+		bytes.NewReader(writeTestSnapshot(t, 1)), decSnapshotInt, decSnapshotString,
+	)
+	sbtest.True(t, errors.Is(err, ErrUninitializedMap))
+}
@@ -0,0 +1,138 @@
+package sbmap
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	slotprobes "github.com/barbell-math/smoothbrain-hashmap/slotProbes"
+)
+
+// Iterates over all of the keys in the map in the order defined by `cmp`. The
+// keys are collected and sorted when iteration starts, so changes made to the
+// map while iterating will not be seen.
+func (m *Map[K, V]) SortedKeys(cmp func(l K, r K) int) iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		for _, s := range m.sortedSlots(cmp) {
+			if !yield(s.key) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over all of the key, value pairs in the map in the key order defined
+// by `cmp`. The pairs are collected and sorted when iteration starts, so changes
+// made to the map while iterating will not be seen.
+func (m *Map[K, V]) SortedAll(cmp func(l K, r K) int) iter.Seq2[K, V] {
+	return func(yield func(k K, v V) bool) {
+		for _, s := range m.sortedSlots(cmp) {
+			if !yield(s.key, s.value) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over the first `n` key, value pairs in the key order defined by
+// `cmp`. Only `n` pairs are kept in a bounded heap while walking the map rather
+// than sorting the entire map, making this cheaper than [Map.SortedAll] when `n`
+// is much smaller than the length of the map. The pairs are collected when
+// iteration starts, so changes made to the map while iterating will not be
+// seen.
+func (m *Map[K, V]) TopK(n int, cmp func(l K, r K) int) iter.Seq2[K, V] {
+	return func(yield func(k K, v V) bool) {
+		if n <= 0 {
+			return
+		}
+
+		// A max heap so the largest of the current top k is always at the
+		// root and can be replaced when a smaller key is found.
+		h := make([]slot[K, V], 0, min(n, m.Len()))
+		for i := range m.groups {
+			for j := range m.groups[i].slots {
+				if m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) != 0b1 {
+					continue
+				}
+				s := m.groups[i].slots[j]
+				if len(h) < n {
+					h = append(h, s)
+					siftUp(h, len(h)-1, cmp)
+				} else if cmp(s.key, h[0].key) < 0 {
+					h[0] = s
+					siftDown(h, 0, cmp)
+				}
+			}
+		}
+
+		slices.SortFunc(h, func(l slot[K, V], r slot[K, V]) int {
+			return cmp(l.key, r.key)
+		})
+		for _, s := range h {
+			if !yield(s.key, s.value) {
+				return
+			}
+		}
+	}
+}
+
+// Returns copies of all of the live slots sorted by key. Copies are returned
+// rather than pointers into the groups so that changes made to the map while
+// the result is being iterated over are not seen.
+func (m *Map[K, V]) sortedSlots(cmp func(l K, r K) int) []slot[K, V] {
+	rv := make([]slot[K, V], 0, m.Len())
+	for i := range m.groups {
+		for j := range m.groups[i].slots {
+			if m.groups[i].flags[j]&(slotprobes.Used|slotprobes.Deleted) == 0b1 {
+				rv = append(rv, m.groups[i].slots[j])
+			}
+		}
+	}
+	slices.SortFunc(rv, func(l slot[K, V], r slot[K, V]) int {
+		return cmp(l.key, r.key)
+	})
+	return rv
+}
+
+func siftUp[K any, V any](h []slot[K, V], i int, cmp func(l K, r K) int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if cmp(h[i].key, h[parent].key) <= 0 {
+			return
+		}
+		h[i], h[parent] = h[parent], h[i]
+		i = parent
+	}
+}
+
+func siftDown[K any, V any](h []slot[K, V], i int, cmp func(l K, r K) int) {
+	for {
+		largest := i
+		if l := 2*i + 1; l < len(h) && cmp(h[l].key, h[largest].key) > 0 {
+			largest = l
+		}
+		if r := 2*i + 2; r < len(h) && cmp(h[r].key, h[largest].key) > 0 {
+			largest = r
+		}
+		if largest == i {
+			return
+		}
+		h[i], h[largest] = h[largest], h[i]
+		i = largest
+	}
+}
+
+// The same as [Map.SortedKeys] using [cmp.Compare] as the ordering.
+func SortedKeys[K cmp.Ordered, V any](m *Map[K, V]) iter.Seq[K] {
+	return m.SortedKeys(cmp.Compare[K])
+}
+
+// The same as [Map.SortedAll] using [cmp.Compare] as the ordering.
+func SortedAll[K cmp.Ordered, V any](m *Map[K, V]) iter.Seq2[K, V] {
+	return m.SortedAll(cmp.Compare[K])
+}
+
+// The same as [Map.TopK] using [cmp.Compare] as the ordering.
+func TopK[K cmp.Ordered, V any](m *Map[K, V], n int) iter.Seq2[K, V] {
+	return m.TopK(n, cmp.Compare[K])
+}
@@ -0,0 +1,105 @@
+package sbmap
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestSortedKeys(t *testing.T) {
+	h := New[int, int]()
+	for _, v := range []int{5, 3, 9, 1, 7} {
+		h.Put(v, v*10)
+	}
+	h.Remove(9)
+
+	sbtest.Eq(t, []int{1, 3, 5, 7}, slices.Collect(SortedKeys(&h)))
+	sbtest.Eq(
+		t, []int{7, 5, 3, 1},
+		slices.Collect(h.SortedKeys(func(l, r int) int { return cmp.Compare(r, l) })),
+	)
+}
+
+func TestSortedAll(t *testing.T) {
+	h := New[string, int]()
+	h.Put("b", 2)
+	h.Put("c", 3)
+	h.Put("a", 1)
+
+	keys := []string{}
+	vals := []int{}
+	for k, v := range SortedAll(&h) {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	sbtest.Eq(t, []string{"a", "b", "c"}, keys)
+	sbtest.Eq(t, []int{1, 2, 3}, vals)
+
+	for range SortedAll(&h) {
+		break
+	}
+}
+
+func TestTopK(t *testing.T) {
+	h := New[int32, int64]()
+	randVals := rand.New(rand.NewSource(3))
+	all := []int32{}
+	for i := 0; i < 1000; i++ {
+		k := randVals.Int31()
+		h.Put(k, int64(k))
+		all = append(all, k)
+	}
+	slices.Sort(all)
+
+	for _, n := range []int{0, 1, 10, 999, 1000, 2000} {
+		keys := []int32{}
+		for k, v := range TopK(&h, n) {
+			sbtest.Eq(t, int64(k), v)
+			keys = append(keys, k)
+		}
+		sbtest.Eq(t, all[:min(n, len(all))], keys)
+	}
+}
+
+func TestSortedIterationIgnoresMutations(t *testing.T) {
+	newMap := func() Map[int, int] {
+		h := New[int, int]()
+		for i := 1; i <= 5; i++ {
+			h.Put(i, i*10)
+		}
+		return h
+	}
+	check := func(seq func(h *Map[int, int]) iter.Seq2[int, int]) {
+		h := newMap()
+		keys := []int{}
+		vals := []int{}
+		for k, v := range seq(&h) {
+			if k == 1 {
+				h.Remove(3)
+				h.Put(4, 400)
+				h.Put(6, 60)
+			}
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		sbtest.Eq(t, []int{1, 2, 3, 4, 5}, keys)
+		sbtest.Eq(t, []int{10, 20, 30, 40, 50}, vals)
+	}
+	check(func(h *Map[int, int]) iter.Seq2[int, int] { return SortedAll(h) })
+	check(func(h *Map[int, int]) iter.Seq2[int, int] { return TopK(h, 5) })
+
+	h := newMap()
+	keys := []int{}
+	for k := range SortedKeys(&h) {
+		if k == 1 {
+			h.Remove(3)
+			h.Put(6, 60)
+		}
+		keys = append(keys, k)
+	}
+	sbtest.Eq(t, []int{1, 2, 3, 4, 5}, keys)
+}
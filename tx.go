@@ -0,0 +1,99 @@
+package sbmap
+
+type (
+	// A buffered write in a transaction. Removes are recorded so that they
+	// hide the value that is in the underlying map.
+	txWrite[K any, V any] struct {
+		key     K
+		value   V
+		removed bool
+	}
+
+	// A set of changes to a [Map] that are applied all at once with
+	// [Tx.Commit] or discarded with [Tx.Rollback]. The map is not changed
+	// until the transaction is committed.
+	Tx[K any, V any] struct {
+		m *Map[K, V]
+		// Maps each key to its write. The writes are kept in a slice rather
+		// than as the value of the map because a Map[K, txWrite[K, V]] here
+		// would be an instantiation cycle through [Map.Begin].
+		index  Map[K, int]
+		writes []txWrite[K, V]
+	}
+)
+
+// Starts a transaction on the map. Changes made through the transaction are
+// buffered and are only applied to the map when [Tx.Commit] is called.
+func (m *Map[K, V]) Begin() Tx[K, V] {
+	return Tx[K, V]{
+		m:     m,
+		index: NewCustom[K, int](_defaultInitialCap, m.eq, m.hash),
+	}
+}
+
+// Gets the value that is related to the supplied key, including any changes
+// made in the transaction. If the key is found the boolean return value will be
+// true and the value will be returned. If the key is not found, or it was
+// removed in the transaction, the boolean return value will be false and a
+// zero-initialized value of type V will be returned.
+func (t *Tx[K, V]) Get(k K) (V, bool) {
+	if i, ok := t.index.Get(k); ok {
+		return t.writes[i].value, !t.writes[i].removed
+	}
+	return t.m.Get(k)
+}
+
+// Buffers placing the supplied key, value pair in the map.
+func (t *Tx[K, V]) Put(k K, v V) {
+	t.write(txWrite[K, V]{key: k, value: v})
+}
+
+// Buffers removing the supplied key from the map.
+func (t *Tx[K, V]) Remove(k K) {
+	t.write(txWrite[K, V]{key: k, removed: true})
+}
+
+func (t *Tx[K, V]) write(w txWrite[K, V]) {
+	if i, ok := t.index.Get(w.key); ok {
+		t.writes[i] = w
+		return
+	}
+	t.index.Put(w.key, len(t.writes))
+	t.writes = append(t.writes, w)
+}
+
+// Applies all of the buffered changes to the map. All removes are applied
+// first, then the map is resized at most once to fit the new keys, then all
+// puts are applied. If no new keys need room the map is instead shrunk at most
+// once if enough keys were removed. The transaction is empty after it is
+// committed and can be Used again.
+func (t *Tx[K, V]) Commit() {
+	newKeys := 0
+	for _, w := range t.writes {
+		groupIdx, slotIdx, ok := t.m.findSlot(w.key)
+		switch {
+		case w.removed && ok:
+			t.m.tombstone(groupIdx, slotIdx)
+		case !w.removed && !ok:
+			newKeys++
+		}
+	}
+
+	resized := t.m.reserve(newKeys)
+	for _, w := range t.writes {
+		if !w.removed {
+			t.m.Put(w.key, w.value)
+		}
+	}
+	if !resized {
+		t.m.shrinkIfSparse()
+	}
+	t.Rollback()
+}
+
+// Discards all of the buffered changes. The map is not changed. The
+// transaction is empty after it is rolled back and can be Used again.
+func (t *Tx[K, V]) Rollback() {
+	t.index = NewCustom[K, int](_defaultInitialCap, t.m.eq, t.m.hash)
+	t.writes = nil
+}
@@ -0,0 +1,122 @@
+package sbmap
+
+import (
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestTxReadsOwnWrites(t *testing.T) {
+	h := New[int, int]()
+	h.Put(1, 1)
+	h.Put(2, 2)
+
+	tx := h.Begin()
+	tx.Put(1, 10)
+	tx.Put(3, 30)
+	tx.Remove(2)
+
+	val, ok := tx.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 10, val)
+	_, ok = tx.Get(2)
+	sbtest.False(t, ok)
+	val, ok = tx.Get(3)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 30, val)
+
+	// The map is not changed until the transaction is committed
+	val, _ = h.Get(1)
+	sbtest.Eq(t, 1, val)
+	_, ok = h.Get(2)
+	sbtest.True(t, ok)
+	_, ok = h.Get(3)
+	sbtest.False(t, ok)
+
+	tx.Remove(3)
+	_, ok = tx.Get(3)
+	sbtest.False(t, ok)
+	tx.Put(2, 20)
+	val, ok = tx.Get(2)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 20, val)
+}
+
+func TestTxCommit(t *testing.T) {
+	h := New[int, int]()
+	for i := range 1000 {
+		h.Put(i, i)
+	}
+
+	tx := h.Begin()
+	for i := range 900 {
+		tx.Remove(i)
+	}
+	for i := 1000; i < 6000; i++ {
+		tx.Put(i, i)
+	}
+	tx.Remove(-1)
+	tx.Commit()
+
+	sbtest.Eq(t, 5100, h.Len())
+	for i := range 900 {
+		_, ok := h.Get(i)
+		sbtest.False(t, ok)
+	}
+	for i := 900; i < 6000; i++ {
+		val, ok := h.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+	// The commit rehashed the map once to fit the live keys so no deleted
+	// slots are left
+	sbtest.Eq(t, 0, h.del)
+
+	// The transaction is empty and can be Used again
+	_, ok := tx.Get(0)
+	sbtest.False(t, ok)
+	tx.Put(0, 0)
+	tx.Commit()
+	sbtest.Eq(t, 5101, h.Len())
+}
+
+func TestTxCommitShrinks(t *testing.T) {
+	h := New[int, int]()
+	for i := range 10000 {
+		h.Put(i, i)
+	}
+	tx := h.Begin()
+	for i := range 9990 {
+		tx.Remove(i)
+	}
+	tx.Commit()
+	sbtest.Eq(t, 10, h.Len())
+	// Only a single halving is done by a commit
+	sbtest.True(t, cap(h.groups) > _defaultInitialCap)
+	for i := 9990; i < 10000; i++ {
+		val, ok := h.Get(i)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i, val)
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	h := New[int, int]()
+	h.Put(1, 1)
+
+	tx := h.Begin()
+	tx.Put(1, 10)
+	tx.Put(2, 20)
+	tx.Remove(1)
+	tx.Rollback()
+
+	_, ok := tx.Get(2)
+	sbtest.False(t, ok)
+	val, ok := tx.Get(1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, 1, val)
+	sbtest.Eq(t, 1, h.Len())
+
+	tx.Commit()
+	sbtest.Eq(t, 1, h.Len())
+}
@@ -0,0 +1,122 @@
+package sbmap
+
+import (
+	"iter"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+type (
+	weakValue[V any] struct {
+		value   V
+		cleanup runtime.Cleanup
+	}
+
+	// Holds the keys that have been garbage collected but have not been
+	// removed from the map yet. Cleanup functions run on their own goroutine
+	// so they only ever touch the graveyard, never the map itself.
+	weakGraveyard[K any] struct {
+		sync.Mutex
+		dead []weak.Pointer[K]
+	}
+
+	// A map that does not keep its keys alive. Keys are held through
+	// [weak.Pointer] values and are compared by pointer identity. Once a key is
+	// garbage collected its entry is removed from the map the next time the map
+	// is Used.
+	//
+	// Like [Map], a WeakMap is not safe for concurrent use.
+	WeakMap[K any, V any] struct {
+		m         Map[weak.Pointer[K], weakValue[V]]
+		graveyard *weakGraveyard[K]
+	}
+)
+
+// Creates a WeakMap where *K is the key type and V is the value type.
+func NewWeak[K any, V any]() WeakMap[K, V] {
+	return WeakMap[K, V]{
+		m: NewCustom[weak.Pointer[K], weakValue[V]](
+			_defaultInitialCap,
+			ComparableEqual[weak.Pointer[K]],
+			ComparableHash[weak.Pointer[K]](),
+		),
+		graveyard: &weakGraveyard[K]{},
+	}
+}
+
+func (g *weakGraveyard[K]) bury(k weak.Pointer[K]) {
+	g.Lock()
+	g.dead = append(g.dead, k)
+	g.Unlock()
+}
+
+// Removes all entries whose keys have been garbage collected.
+func (m *WeakMap[K, V]) reap() {
+	m.graveyard.Lock()
+	dead := m.graveyard.dead
+	m.graveyard.dead = nil
+	m.graveyard.Unlock()
+
+	for _, k := range dead {
+		m.m.Remove(k)
+	}
+}
+
+// Returns the number of elements in the map. Keys that have been garbage
+// collected are not counted once their cleanup has run.
+func (m *WeakMap[K, V]) Len() int {
+	m.reap()
+	return m.m.Len()
+}
+
+// Gets the value that is related to the supplied key. If the key is found the
+// boolean return value will be true and the value will be returned.
+func (m *WeakMap[K, V]) Get(k *K) (V, bool) {
+	m.reap()
+	v, ok := m.m.Get(weak.Make(k))
+	return v.value, ok
+}
+
+// Places the supplied key, value pair in the map. If the key was already
+// present in the map the old value will be overwritten. The key must not be
+// nil.
+func (m *WeakMap[K, V]) Put(k *K, v V) {
+	m.reap()
+	wk := weak.Make(k)
+	if groupIdx, slotIdx, ok := m.m.findSlot(wk); ok {
+		m.m.groups[groupIdx].slots[slotIdx].value.value = v
+		return
+	}
+
+	g := m.graveyard
+	m.m.Put(wk, weakValue[V]{
+		value:   v,
+		cleanup: runtime.AddCleanup(k, g.bury, wk),
+	})
+}
+
+// Removes the supplied key and associated value from the map if it is present.
+func (m *WeakMap[K, V]) Remove(k *K) {
+	m.reap()
+	wk := weak.Make(k)
+	if groupIdx, slotIdx, ok := m.m.findSlot(wk); ok {
+		m.m.groups[groupIdx].slots[slotIdx].value.cleanup.Stop()
+		m.m.Remove(wk)
+	}
+}
+
+// Iterates over all of the key, value pairs in the map whose keys are still
+// reachable. Uses the stdlib `iter` package so this function can be Used in a
+// standard `for` loop.
+func (m *WeakMap[K, V]) All() iter.Seq2[*K, V] {
+	return func(yield func(k *K, v V) bool) {
+		m.reap()
+		for s := range m.m.liveSlots() {
+			k := s.key.Value()
+			if k != nil && !yield(k, s.value.value) {
+				return
+			}
+		}
+	}
+}
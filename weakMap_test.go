@@ -0,0 +1,75 @@
+package sbmap
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+// Forces garbage collection until the map shrinks to the expected length or the
+// attempts run out. Cleanups run on their own goroutine so they may not have run
+// right after a single GC cycle.
+func gcUntilLen[K any, V any](m *WeakMap[K, V], l int) {
+	for i := 0; i < 100 && m.Len() > l; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWeakMapGetPutRemove(t *testing.T) {
+	h := NewWeak[int, string]()
+	k1, k2 := new(int), new(int)
+
+	h.Put(k1, "one")
+	h.Put(k2, "two")
+	h.Put(k1, "uno")
+	sbtest.Eq(t, 2, h.Len())
+
+	val, ok := h.Get(k1)
+	sbtest.True(t, ok)
+	sbtest.Eq(t, "uno", val)
+	_, ok = h.Get(new(int))
+	sbtest.False(t, ok)
+
+	h.Remove(k1)
+	sbtest.Eq(t, 1, h.Len())
+	_, ok = h.Get(k1)
+	sbtest.False(t, ok)
+
+	cntr := 0
+	for k, v := range h.All() {
+		sbtest.True(t, k == k2)
+		sbtest.Eq(t, "two", v)
+		cntr++
+	}
+	sbtest.Eq(t, 1, cntr)
+	runtime.KeepAlive(k1)
+	runtime.KeepAlive(k2)
+}
+
+func TestWeakMapCollectedKeysAreRemoved(t *testing.T) {
+	h := NewWeak[[64]byte, int]()
+	kept := make([]*[64]byte, 0, 50)
+	for i := range 100 {
+		k := new([64]byte)
+		h.Put(k, i)
+		if i%2 == 0 {
+			kept = append(kept, k)
+		}
+	}
+	sbtest.Eq(t, 100, h.Len())
+
+	gcUntilLen(&h, 50)
+	sbtest.Eq(t, 50, h.Len())
+	for i, k := range kept {
+		val, ok := h.Get(k)
+		sbtest.True(t, ok)
+		sbtest.Eq(t, i*2, val)
+	}
+
+	kept = nil
+	gcUntilLen(&h, 0)
+	sbtest.Eq(t, 0, h.Len())
+}